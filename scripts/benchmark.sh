@@ -12,9 +12,124 @@ NC='\033[0m' # No Color
 # Configuration
 PORT=8080
 WARMUP_TIME=5
+# Warm-up runs until throughput and P99 settle, for at least WARMUP_TIME and
+# at most WARMUP_MAX seconds (empty = max_seconds of the warmup settings in
+# benchmark.json)
+WARMUP_MAX=""
 BENCHMARK_DURATION=30
 CONNECTIONS=100
 THREADS=4
+PIPELINE_DEPTH=1
+LOAD_GENERATOR="wrk"
+LOADGEN_BIN="./bin/loadgen"
+# The build-tagged side listeners of the Go servers; every server has a copy
+# of the ones in SERVER_FILES_DIR
+SERVER_FILES_DIR="./templates/go-template"
+SERVER_FILES="profile.go metrics.go"
+SLOW_CLIENTS=0
+SLOW_INTERVAL="500ms"
+TRACE_RATE=0
+SHOW_HEATMAP=false
+PROFILE=false
+PGO=false
+CPU_SWEEP=false
+COLD_STARTS=0
+FOOTPRINT=false
+SHUTDOWN_TEST=false
+COLDSTART_BIN="./bin/coldstart"
+
+# CPUs the server is pinned to (taskset list, empty = unpinned) and the
+# GOMAXPROCS given to Go servers (empty = Go's default)
+SERVER_CPUS=""
+SERVER_GOMAXPROCS=""
+
+# --gc-matrix reruns every Go server under each GOGC and GOMEMLIMIT value of
+# the gc_matrix in benchmark.json; the values given to the server being
+# benchmarked (empty = Go's default)
+GC_MATRIX=false
+SERVER_GOGC=""
+SERVER_GOMEMLIMIT=""
+
+# Run order: frameworks run in configuration order, or shuffled with a
+# seeded generator (--shuffle, --seed) so that the same seed reproduces the
+# order. --iterations repeats every framework, back to back or interleaved
+# (--interleave: A B C B C A, one position later every round), with COOLDOWN
+# seconds of idle time between servers.
+SHUFFLE=false
+RUN_SEED=""
+ITERATIONS=1
+INTERLEAVE=false
+COOLDOWN=0
+RNG_STATE=0
+FRAMEWORK_QUEUE=()
+RUN_SEQUENCE=()
+
+# Load generator calibration: before the frameworks, the load generator runs
+# against scripts/cannedserver, which answers every request with canned
+# bytes, to measure the generator's own ceiling on this machine (empty = not
+# calibrated, --no-calibration). Results within LOADGEN_BOUND_MARGIN percent
+# of it are reported as load-generator bound. LOADGEN_CPU is the share of
+# its CPUs the load generator used in the last run, in percent.
+CALIBRATE=true
+CANNEDSERVER_BIN="./bin/cannedserver"
+CALIBRATION_PORT=18090
+LOADGEN_CEILING=""
+LOADGEN_CEILING_CPU=""
+LOADGEN_BOUND_MARGIN=""
+LOADGEN_CPU_SATURATION=""
+LOADGEN_CPU=""
+LOADGEN_CPU_START=""
+CLOCK_TICKS=$(getconf CLK_TCK 2>/dev/null || echo 100)
+
+# Host fingerprint recorded in the results configuration, and the noise
+# warnings of the pre-flight checks (fatal with --strict-host)
+HOST_JSON="{}"
+HOST_WARNINGS=()
+STRICT_HOST=false
+
+# --isolate-cpus splits the CPUs into disjoint sets for the load generator
+# and the servers (taskset lists, empty = no isolation)
+ISOLATE_CPUS=false
+LOADGEN_CPUS=""
+ISOLATED_SERVER_CPUS=""
+PPROF_PORT=6060
+PROFILE_TOP=20
+
+# Load test profile from benchmark.json (--load-profile). Profiles with
+# "memory_sampling" sample every server's memory for leak detection; Go
+# servers are then built with metrics.go, which serves runtime/metrics on
+# METRICS_PORT.
+LOAD_PROFILE=""
+MEMORY_SAMPLING=false
+METRICS_PORT=6061
+CURRENT_METRICS=false
+MEMORY_SAMPLES=""
+MEMORY_SAMPLER_PID=""
+
+# GC and scheduler telemetry of Go servers (--gc-telemetry): scripts/gcwatch
+# samples metrics.go during every endpoint run
+GC_TELEMETRY=false
+GCWATCH_BIN="./bin/gcwatch"
+GC_WATCH_PID=""
+GC_WATCH_FILE=""
+GC_JSON=""
+
+# cgroup v2 limits: --resource-profile from benchmark.json, overridden by a
+# framework's own "resource_limits". SERVER_LIMITS holds "<cpus> <memory>"
+# for the framework being benchmarked and SERVER_CGROUP the cgroup its
+# server runs in (empty = unlimited)
+RESOURCE_PROFILE=""
+CGROUP_PARENT=""
+SERVER_LIMITS=""
+SERVER_CGROUP=""
+NETWORK_PROFILE="loopback"
+PROXY_PORT=18080
+FAULTPROXY_BIN="./bin/faultproxy"
+PROXY_PID=""
+
+# Port the load generator targets: the server itself, or the fault-injecting
+# proxy in front of it when a network profile is active
+TARGET_PORT=$PORT
 
 # Results directory
 RESULTS_DIR="./results"
@@ -24,6 +139,20 @@ mkdir -p "$RESULTS_DIR"
 TIMESTAMP=$(date +%Y%m%d_%H%M%S)
 RESULTS_FILE="$RESULTS_DIR/benchmark_$TIMESTAMP.json"
 
+# HdrHistogram-style percentile distributions of the run, one file per
+# framework and endpoint
+DISTRIBUTION_DIR="${RESULTS_FILE%.json}_distributions"
+CURRENT_FRAMEWORK=""
+
+# pprof profiles of Go servers (--profile), one directory per framework
+PROFILE_DIR="${RESULTS_FILE%.json}"
+CURRENT_PPROF=false
+PROFILE_PIDS=()
+PROFILES_JSON=""
+
+# wrk script reporting the latency tail and percentile spectrum
+WRK_PERCENTILES_SCRIPT="./scripts/wrk/percentiles.lua"
+
 # Function to print colored output
 print_status() {
     echo -e "${BLUE}[INFO]${NC} $1"
@@ -84,6 +213,203 @@ cleanup_port() {
     fi
 }
 
+# Function to name the HdrHistogram-style distribution file of one endpoint
+distribution_file() {
+    local description=$1
+    local slug=$(echo "$description" | tr '[:upper:] ' '[:lower:]-')
+
+    mkdir -p "$DISTRIBUTION_DIR"
+    echo "$DISTRIBUTION_DIR/${CURRENT_FRAMEWORK}_${slug}.hgrm"
+}
+
+# Function to extract one latency line ("99%", "99.9%", "max", ...) from wrk output
+wrk_percentile() {
+    echo "$1" | awk -v label="$2" '$1 == label {print $2; exit}'
+}
+
+# Function to print the latency fields of a wrk result: the percentiles of
+# --latency plus the tail and spectrum reported by percentiles.lua
+wrk_latency_json() {
+    local output=$1
+    local spectrum=$(echo "$output" | sed -n 's/^ *Percentile spectrum (us): //p' | tr ' ' '\n' | \
+        awk -F= 'NF == 2 {printf "%s{\"percentile\": %s, \"latency_us\": %s}", (n++ ? ", " : ""), $1, $2}')
+
+    cat << EOF
+  "latency_percentiles": {
+    "50%": "$(wrk_percentile "$output" "50%")",
+    "75%": "$(wrk_percentile "$output" "75%")",
+    "90%": "$(wrk_percentile "$output" "90%")",
+    "99%": "$(wrk_percentile "$output" "99%")",
+    "99.9%": "$(wrk_percentile "$output" "99.9%")",
+    "99.99%": "$(wrk_percentile "$output" "99.99%")",
+    "max": "$(wrk_percentile "$output" max)"
+  },
+  "percentile_spectrum": [$spectrum],
+EOF
+}
+
+# Function to name the profile files of one endpoint run
+profile_prefix() {
+    local slug=$(echo "$1" | tr '[:upper:] ' '[:lower:]-')
+
+    mkdir -p "$PROFILE_DIR/$CURRENT_FRAMEWORK"
+    echo "$PROFILE_DIR/$CURRENT_FRAMEWORK/$slug"
+}
+
+# Function to start capturing pprof profiles for one endpoint run. CPU,
+# allocs, mutex and block profiles cover the run itself; the heap profile is
+# a snapshot taken by finish_profile_capture once the load stops.
+start_profile_capture() {
+    PROFILE_PIDS=()
+    PROFILES_JSON=""
+    if [ "$CURRENT_PPROF" != true ]; then
+        return 0
+    fi
+
+    local prefix=$(profile_prefix "$1")
+    local seconds=$(( BENCHMARK_DURATION > 2 ? BENCHMARK_DURATION - 1 : 1 ))
+    local base="http://localhost:$PPROF_PORT/debug/pprof"
+
+    curl -s -o "$prefix.cpu.pprof" "$base/profile?seconds=$seconds" &
+    PROFILE_PIDS+=($!)
+    for kind in allocs mutex block; do
+        curl -s -o "$prefix.$kind.pprof" "$base/$kind?seconds=$seconds" &
+        PROFILE_PIDS+=($!)
+    done
+}
+
+# Function to finish a capture: wait for the profiles, take the heap
+# snapshot and write a top-N function summary next to every profile. Sets
+# PROFILES_JSON for the endpoint result.
+finish_profile_capture() {
+    if [ "$CURRENT_PPROF" != true ]; then
+        return 0
+    fi
+
+    local prefix=$(profile_prefix "$1")
+    wait "${PROFILE_PIDS[@]}" 2>/dev/null || true
+    curl -s -o "$prefix.heap.pprof" "http://localhost:$PPROF_PORT/debug/pprof/heap" || true
+
+    local fields=()
+    for kind in cpu heap allocs mutex block; do
+        local file="$prefix.$kind.pprof"
+        if [ ! -s "$file" ]; then
+            print_warning "No $kind profile captured for: $1"
+            continue
+        fi
+        go tool pprof -top -nodecount="$PROFILE_TOP" "$file" > "$prefix.$kind.top.txt" 2>&1 || true
+        fields+=("\"$kind\": {\"file\": \"$file\", \"summary\": \"$prefix.$kind.top.txt\"}")
+    done
+
+    local IFS=,
+    PROFILES_JSON="{${fields[*]}}"
+    print_status "Profiles saved to: $(dirname "$prefix")"
+}
+
+# Function to print the "profiles" field of an endpoint result, if any
+profiles_field() {
+    if [ -n "$PROFILES_JSON" ]; then
+        echo "  \"profiles\": $PROFILES_JSON,"
+    fi
+}
+
+# Function to start sampling GC and scheduler metrics of a Go server for one
+# endpoint run
+start_gc_capture() {
+    GC_WATCH_PID=""
+    GC_JSON=""
+    if [ "$GC_TELEMETRY" != true ] || [ "$CURRENT_METRICS" != true ]; then
+        return 0
+    fi
+
+    GC_WATCH_FILE=$(mktemp)
+    "$GCWATCH_BIN" -url "http://localhost:$METRICS_PORT/debug/metrics" -o "$GC_WATCH_FILE" 2>/dev/null &
+    GC_WATCH_PID=$!
+}
+
+# Function to stop the GC sampler and set GC_JSON for the endpoint result
+finish_gc_capture() {
+    if [ -z "$GC_WATCH_PID" ]; then
+        return 0
+    fi
+
+    kill -TERM "$GC_WATCH_PID" 2>/dev/null || true
+    wait "$GC_WATCH_PID" 2>/dev/null || true
+    GC_WATCH_PID=""
+
+    if [ -s "$GC_WATCH_FILE" ]; then
+        GC_JSON=$(cat "$GC_WATCH_FILE")
+        print_status "GC: $(jq -r '"\(.gc_cycles) cycles, \(.pause_total_ms * 100 | round / 100)ms paused, p99 pause \(.pause_p99_us | round)us"' "$GC_WATCH_FILE")"
+    else
+        print_warning "No GC telemetry captured for: $1"
+    fi
+    rm -f "$GC_WATCH_FILE"
+}
+
+# Function to print the "gc" field of an endpoint result, if any
+gc_field() {
+    if [ -n "$GC_JSON" ]; then
+        echo "  \"gc\": $GC_JSON,"
+    fi
+}
+
+# Function to print the CPU time, in clock ticks, of the children a shell
+# (the one running the load generator, $BASHPID) has waited for. The load
+# generator is the only child that finishes during a run, so the difference
+# across a run is its CPU time.
+child_cpu_ticks() {
+    if [ -r "/proc/$1/stat" ]; then
+        # cutime and cstime, counted from the field after the command name
+        sed 's/.*) //' "/proc/$1/stat" | awk '{ print $14 + $15 }'
+    fi
+}
+
+# Function to print how many CPUs the load generator can keep busy: one per
+# thread, up to the CPUs it is pinned to
+loadgen_cpu_capacity() {
+    local cpus
+    if [ -n "$LOADGEN_CPUS" ]; then
+        cpus=$(echo "$LOADGEN_CPUS" | tr ',' '\n' | wc -l)
+    else
+        cpus=$(getconf _NPROCESSORS_ONLN 2>/dev/null || echo 1)
+    fi
+    echo $(( THREADS < cpus ? THREADS : cpus ))
+}
+
+# Function to start measuring the CPU use of the load generator for one run
+start_loadgen_cpu() {
+    LOADGEN_CPU=""
+    local shell_pid=$BASHPID
+    LOADGEN_CPU_START="$(now_ms) $(child_cpu_ticks $shell_pid)"
+}
+
+# Function to set LOADGEN_CPU for the run that just finished, and warn when
+# the load generator was saturated
+finish_loadgen_cpu() {
+    local start_ms start_ticks
+    read -r start_ms start_ticks <<< "$LOADGEN_CPU_START"
+    local shell_pid=$BASHPID
+    local ticks=$(child_cpu_ticks $shell_pid)
+    if [ -z "$start_ticks" ] || [ -z "$ticks" ]; then
+        return 0
+    fi
+
+    local elapsed_ms=$(( $(now_ms) - start_ms ))
+    LOADGEN_CPU=$(awk -v ticks=$((ticks - start_ticks)) -v hz="$CLOCK_TICKS" -v ms="$elapsed_ms" -v cpus="$(loadgen_cpu_capacity)" \
+        'BEGIN { printf "%.1f", (ms > 0 ? ticks / hz / (ms / 1000) / cpus * 100 : 0) }')
+    if awk -v cpu="$LOADGEN_CPU" -v max="$LOADGEN_CPU_SATURATION" 'BEGIN { exit !(cpu >= max) }'; then
+        print_warning "Load generator used ${LOADGEN_CPU}% of its CPUs during: $1; the result may measure the load generator rather than the server"
+    fi
+}
+
+# Function to print the "loadgen_cpu_percent" field of an endpoint result, if
+# measured
+loadgen_cpu_field() {
+    if [ -n "$LOADGEN_CPU" ]; then
+        echo "  \"loadgen_cpu_percent\": $LOADGEN_CPU,"
+    fi
+}
+
 # Function to run wrk benchmark
 run_wrk() {
     local url=$1
@@ -94,20 +420,22 @@ run_wrk() {
     print_status "Duration: ${BENCHMARK_DURATION}s, Connections: $CONNECTIONS, Threads: $THREADS"
 
     # Run wrk and capture output
+    local hgrm_file=$(distribution_file "$description")
     local wrk_output
-    wrk_output=$(wrk -t$THREADS -c$CONNECTIONS -d${BENCHMARK_DURATION}s --latency "$url" 2>&1)
+    start_profile_capture "$description"
+    start_gc_capture "$description"
+    start_loadgen_cpu
+    wrk_output=$(WRK_HGRM_FILE="$hgrm_file" ${LOADGEN_CPUS:+taskset -c $LOADGEN_CPUS} wrk -t$THREADS -c$CONNECTIONS -d${BENCHMARK_DURATION}s --latency \
+        -s "$WRK_PERCENTILES_SCRIPT" "$url" 2>&1)
+    finish_loadgen_cpu "$description"
+    finish_gc_capture "$description"
+    finish_profile_capture "$description"
 
     # Parse wrk output
     local requests_per_sec=$(echo "$wrk_output" | grep "Requests/sec:" | awk '{print $2}')
     local avg_latency=$(echo "$wrk_output" | grep "Latency" | head -1 | awk '{print $2}')
     local transfer_per_sec=$(echo "$wrk_output" | grep "Transfer/sec:" | awk '{print $2}')
 
-    # Get additional latency percentiles
-    local latency_50=$(echo "$wrk_output" | grep "50%" | awk '{print $2}')
-    local latency_75=$(echo "$wrk_output" | grep "75%" | awk '{print $2}')
-    local latency_90=$(echo "$wrk_output" | grep "90%" | awk '{print $2}')
-    local latency_99=$(echo "$wrk_output" | grep "99%" | awk '{print $2}')
-
     # Store results in temporary file
     # Escape the raw output properly for JSON
     local escaped_output=$(echo "$wrk_output" | sed 's/\\/\\\\/g; s/"/\\"/g' | awk '{printf "%s\\n", $0}' | sed 's/\\n$//')
@@ -119,12 +447,11 @@ run_wrk() {
   "requests_per_sec": "$requests_per_sec",
   "avg_latency": "$avg_latency",
   "transfer_per_sec": "$transfer_per_sec",
-  "latency_percentiles": {
-    "50%": "$latency_50",
-    "75%": "$latency_75",
-    "90%": "$latency_90",
-    "99%": "$latency_99"
-  },
+$(wrk_latency_json "$wrk_output")
+  "distribution_file": "$hgrm_file",
+$(profiles_field)
+$(gc_field)
+$(loadgen_cpu_field)
   "raw_output": "$escaped_output"
 },
 EOF
@@ -132,13 +459,416 @@ EOF
     print_success "Benchmark completed: $requests_per_sec req/sec"
 }
 
-# Function to benchmark a server
+# Function to run wrk POST benchmark with a JSON payload
+run_wrk_post() {
+    local url=$1
+    local description=$2
+
+    print_status "Running POST benchmark: $description"
+    local hgrm_file=$(distribution_file "$description")
+    local post_output
+    start_profile_capture "$description"
+    start_gc_capture "$description"
+    start_loadgen_cpu
+    post_output=$(WRK_HGRM_FILE="$hgrm_file" ${LOADGEN_CPUS:+taskset -c $LOADGEN_CPUS} wrk -t$THREADS -c$CONNECTIONS -d${BENCHMARK_DURATION}s --latency \
+        -s <(cat "$WRK_PERCENTILES_SCRIPT"; echo 'wrk.method = "POST"; wrk.body = "{\"name\":\"Test User\"}"; wrk.headers["Content-Type"] = "application/json"') \
+        "$url" 2>&1)
+    finish_loadgen_cpu "$description"
+    finish_gc_capture "$description"
+    finish_profile_capture "$description"
+
+    local post_rps=$(echo "$post_output" | grep "Requests/sec:" | awk '{print $2}')
+    local post_latency=$(echo "$post_output" | grep "Latency" | head -1 | awk '{print $2}')
+
+    # Escape the POST output properly for JSON
+    local escaped_post_output=$(echo "$post_output" | sed 's/\\/\\\\/g; s/"/\\"/g' | awk '{printf "%s\\n", $0}' | sed 's/\\n$//')
+
+    cat << EOF >> "$TEMP_RESULTS"
+{
+  "endpoint": "$description",
+  "url": "$url",
+  "requests_per_sec": "$post_rps",
+  "avg_latency": "$post_latency",
+  "transfer_per_sec": "$(echo "$post_output" | grep "Transfer/sec:" | awk '{print $2}')",
+$(wrk_latency_json "$post_output")
+  "distribution_file": "$hgrm_file",
+$(profiles_field)
+$(gc_field)
+$(loadgen_cpu_field)
+  "raw_output": "$escaped_post_output"
+},
+EOF
+
+    print_success "Benchmark completed: $post_rps req/sec"
+}
+
+# Function to build one of the Go tools under scripts/
+build_go_tool() {
+    local package=$1
+    local bin=$2
+
+    mkdir -p "$(dirname "$bin")"
+    local bin_path="$(cd "$(dirname "$bin")" && pwd)/$(basename "$bin")"
+    if ! (cd scripts && go build -o "$bin_path" "./$package"); then
+        print_error "Failed to build scripts/$package"
+        exit 1
+    fi
+}
+
+# Function to refresh a Go server's copies of the build-tagged side listeners
+# from SERVER_FILES_DIR, so a tagged build never runs a stale copy
+sync_server_files() {
+    local server_dir=$1
+    local file
+
+    for file in $SERVER_FILES; do
+        if [ -f "$server_dir/$file" ] && ! cmp -s "$SERVER_FILES_DIR/$file" "$server_dir/$file"; then
+            print_warning "$server_dir/$file differs from $SERVER_FILES_DIR/$file, building with the latter"
+            cp "$SERVER_FILES_DIR/$file" "$server_dir/$file"
+        fi
+    done
+}
+
+# Function to build the Go load generator (scripts/loadgen)
+build_loadgen() {
+    print_status "Building Go load generator..."
+    build_go_tool loadgen "$LOADGEN_BIN"
+}
+
+# Function to measure the ceiling of the load generator: the throughput it
+# reaches on / of scripts/cannedserver, with the settings of the benchmark
+# runs. Sets LOADGEN_CEILING and LOADGEN_CEILING_CPU.
+calibrate_load_generator() {
+    if [ "$CALIBRATE" != true ]; then
+        return 0
+    fi
+
+    print_status "Calibrating the load generator against a canned-response server..."
+    build_go_tool cannedserver "$CANNEDSERVER_BIN"
+    ${ISOLATED_SERVER_CPUS:+taskset -c $ISOLATED_SERVER_CPUS} "$CANNEDSERVER_BIN" -addr ":$CALIBRATION_PORT" &
+    local server_pid=$!
+    local attempt
+    for attempt in $(seq 20); do
+        curl -s -o /dev/null "http://localhost:$CALIBRATION_PORT/" && break
+        sleep 0.25
+    done
+
+    local results_file=$(mktemp)
+    local temp_results=$TEMP_RESULTS
+    TEMP_RESULTS=$results_file
+    CURRENT_FRAMEWORK="calibration"
+    if [ "$LOAD_GENERATOR" = "go" ]; then
+        run_loadgen "http://localhost:$CALIBRATION_PORT/" "Calibration"
+    else
+        run_wrk "http://localhost:$CALIBRATION_PORT/" "Calibration"
+    fi
+    TEMP_RESULTS=$temp_results
+
+    kill -TERM "$server_pid" 2>/dev/null || true
+    wait "$server_pid" 2>/dev/null || true
+
+    LOADGEN_CEILING=$(grep -m1 '"requests_per_sec"' "$results_file" | sed 's/.*: *"\{0,1\}\([0-9.]*\).*/\1/')
+    LOADGEN_CEILING_CPU=$LOADGEN_CPU
+    rm -f "$results_file"
+    if [ -z "$LOADGEN_CEILING" ]; then
+        print_warning "Load generator calibration failed; results are not checked against its ceiling"
+        return 0
+    fi
+
+    print_status "Load generator ceiling: $LOADGEN_CEILING req/sec (${LOADGEN_CEILING_CPU:-unknown}% of its CPUs); results within ${LOADGEN_BOUND_MARGIN}% of it are load-generator bound"
+}
+
+# Function to put the fault-injecting proxy (scripts/faultproxy) between the
+# load generator and the servers, configured from a network profile in
+# benchmark.json
+start_network_proxy() {
+    local config_file="./benchmark.json"
+
+    if [ "$NETWORK_PROFILE" = "loopback" ]; then
+        TARGET_PORT=$PORT
+        return
+    fi
+
+    if ! command -v jq >/dev/null 2>&1 || [ ! -f "$config_file" ]; then
+        print_error "Network profiles need jq and $config_file"
+        exit 1
+    fi
+
+    local profile=$(jq -c ".benchmark.network_profiles[\"$NETWORK_PROFILE\"] // empty" "$config_file")
+    if [ -z "$profile" ]; then
+        print_error "Unknown network profile: $NETWORK_PROFILE"
+        print_status "Available profiles: $(jq -r '.benchmark.network_profiles | keys | join(", ")' "$config_file")"
+        exit 1
+    fi
+
+    print_status "Building fault-injecting proxy..."
+    build_go_tool faultproxy "$FAULTPROXY_BIN"
+
+    ${LOADGEN_CPUS:+taskset -c $LOADGEN_CPUS} "$FAULTPROXY_BIN" -listen ":$PROXY_PORT" -target "localhost:$PORT" \
+        -latency "$(echo "$profile" | jq -r '.latency // "0s"')" \
+        -jitter "$(echo "$profile" | jq -r '.jitter // "0s"')" \
+        -bandwidth-kbps "$(echo "$profile" | jq -r '.bandwidth_kbps // 0')" \
+        -segment-bytes "$(echo "$profile" | jq -r '.segment_bytes // 0')" \
+        -reset-probability "$(echo "$profile" | jq -r '.reset_probability // 0')" &
+    PROXY_PID=$!
+    sleep 1
+
+    if ! kill -0 "$PROXY_PID" 2>/dev/null; then
+        print_error "Fault-injecting proxy failed to start on port $PROXY_PORT"
+        exit 1
+    fi
+
+    TARGET_PORT=$PROXY_PORT
+    print_success "Network profile '$NETWORK_PROFILE': $(echo "$profile" | jq -r '.description // ""')"
+}
+
+stop_network_proxy() {
+    if [ -n "$PROXY_PID" ]; then
+        kill "$PROXY_PID" 2>/dev/null || true
+        wait "$PROXY_PID" 2>/dev/null || true
+        PROXY_PID=""
+    fi
+}
+
+# Function to run the Go load generator against one endpoint. Any arguments
+# after the body are passed through to loadgen.
+run_loadgen() {
+    local url=$1
+    local description=$2
+    local method=${3:-GET}
+    local body=${4:-}
+    shift $(( $# < 4 ? $# : 4 ))
+
+    print_status "Running benchmark: $description"
+    print_status "URL: $url"
+    print_status "Duration: ${BENCHMARK_DURATION}s, Connections: $CONNECTIONS, Threads: $THREADS, Pipeline depth: $PIPELINE_DEPTH"
+
+    local args=(-name "$description" -url "$url" -method "$method"
+        -c "$CONNECTIONS" -t "$THREADS" -d "${BENCHMARK_DURATION}s"
+        -pipeline "$PIPELINE_DEPTH" -trace-rate "$TRACE_RATE"
+        -hgrm "$(distribution_file "$description")")
+    if [ -n "$body" ]; then
+        args+=(-body "$body" -H "Content-Type: application/json")
+    fi
+    args+=("$@")
+
+    # loadgen prints its report, including the latency heatmap, on stderr
+    local report=/dev/null
+    if [ "$SHOW_HEATMAP" = true ]; then
+        report=/dev/stderr
+    fi
+
+    local result_file
+    result_file=$(mktemp)
+    start_profile_capture "$description"
+    start_gc_capture "$description"
+    start_loadgen_cpu
+    if ! ${LOADGEN_CPUS:+taskset -c $LOADGEN_CPUS} "$LOADGEN_BIN" "${args[@]}" -o "$result_file" 2>"$report"; then
+        print_error "Load generator failed for: $description"
+        rm -f "$result_file"
+        finish_gc_capture "$description"
+        finish_profile_capture "$description"
+        return 0
+    fi
+    finish_loadgen_cpu "$description"
+    finish_gc_capture "$description"
+    finish_profile_capture "$description"
+
+    if [ -n "$LOADGEN_CPU" ]; then
+        sed -i.bak "1 a\\$(loadgen_cpu_field)" "$result_file" && rm -f "$result_file.bak"
+    fi
+    if [ -n "$PROFILES_JSON" ]; then
+        jq --argjson profiles "$PROFILES_JSON" '.profiles = $profiles' "$result_file" > "$result_file.tmp"
+        mv "$result_file.tmp" "$result_file"
+    fi
+    if [ -n "$GC_JSON" ]; then
+        jq -c --argjson gc "$GC_JSON" '.gc = $gc' "$result_file" > "$result_file.tmp"
+        mv "$result_file.tmp" "$result_file"
+    fi
+
+    local requests_per_sec=$(jq -r '.requests_per_sec' "$result_file" 2>/dev/null || grep '"requests_per_sec"' "$result_file" | cut -d'"' -f4)
+    sed '$ s/$/,/' "$result_file" >> "$TEMP_RESULTS"
+    rm -f "$result_file"
+
+    print_success "Benchmark completed: $requests_per_sec req/sec"
+}
+
+# Function to measure how slow clients degrade legitimate load. loadgen runs
+# a baseline first, then the same load with slow clients attached.
+run_slow_client_test() {
+    local url=$1
+
+    print_status "Running slow-client resilience test with $SLOW_CLIENTS slow clients (one byte every $SLOW_INTERVAL)"
+    run_loadgen "$url" "Slow-client resilience" GET "" \
+        -slow-clients "$SLOW_CLIENTS" -slow-interval "$SLOW_INTERVAL"
+}
+
+# Function to print the resident memory (KB) of a process and everything it
+# started, so wrappers such as `bun run` or `go run` are counted with the
+# server
+process_tree_rss_kb() {
+    local root=$1
+
+    ps -A -o pid= -o ppid= -o rss= | awk -v root="$root" '
+        { parent[$1] = $2; rss[$1] = $3 }
+        END {
+            for (pid in rss) {
+                for (p = pid; p != "" && p != 0 && p != 1; p = parent[p]) {
+                    if (p == root) { total += rss[pid]; break }
+                }
+            }
+            print total + 0
+        }'
+}
+
+# Function to start sampling a server's memory in the background: RSS of its
+# process tree and, for Go servers with metrics.go, heap in use from
+# runtime/metrics. One "<ms> <rss bytes> <heap bytes>" line per sample.
+start_memory_sampler() {
+    local server_pid=$1
+    local interval=$(jq -r '.benchmark.leak_detection.sample_interval_seconds // 1' ./benchmark.json)
+    local metrics_url=""
+    if [ "$CURRENT_METRICS" = true ]; then
+        metrics_url="http://localhost:$METRICS_PORT/debug/metrics"
+    fi
+
+    MEMORY_SAMPLES=$(mktemp)
+    (
+        local started=$(now_ms)
+        while kill -0 "$server_pid" 2>/dev/null; do
+            local rss_kb=$(process_tree_rss_kb "$server_pid")
+            local heap=0
+            if [ -n "$metrics_url" ]; then
+                heap=$(curl -s --max-time 1 "$metrics_url" | \
+                    jq '.["/memory/classes/heap/objects:bytes"] + .["/memory/classes/heap/unused:bytes"]' 2>/dev/null || echo 0)
+            fi
+            echo "$(( $(now_ms) - started )) $((rss_kb * 1024)) ${heap:-0}" >> "$MEMORY_SAMPLES"
+            sleep "$interval"
+        done
+    ) &
+    MEMORY_SAMPLER_PID=$!
+    print_status "Sampling memory every ${interval}s (PID $server_pid)"
+}
+
+# Function to print the least-squares growth of one column of the memory
+# samples as "<MB per minute> <r squared>", skipping the warm-up share of
+# samples where heaps and caches legitimately grow
+memory_trend() {
+    local column=$1
+    local warmup=$2
+
+    awk -v col="$column" -v warmup="$warmup" '
+        { t[NR] = $1 / 60000; v[NR] = $col / 1048576 }
+        END {
+            for (i = int(NR * warmup) + 1; i <= NR; i++) {
+                n++; sx += t[i]; sy += v[i]; sxx += t[i] * t[i]; sxy += t[i] * v[i]; syy += v[i] * v[i]
+            }
+            d = n * sxx - sx * sx
+            if (n < 3 || d == 0) { print "0 0"; exit }
+            slope = (n * sxy - sx * sy) / d
+            spread = d * (n * syy - sy * sy)
+            printf "%.4f %.4f\n", slope, (spread > 0 ? (n * sxy - sx * sy) ^ 2 / spread : 0)
+        }' "$MEMORY_SAMPLES"
+}
+
+# Function to stop the memory sampler, fit the memory growth and record the
+# samples and verdict in framework_info
+stop_memory_sampler() {
+    local server_name=$1
+
+    kill "$MEMORY_SAMPLER_PID" 2>/dev/null || true
+    wait "$MEMORY_SAMPLER_PID" 2>/dev/null || true
+    MEMORY_SAMPLER_PID=""
+
+    local settings=$(jq -c '.benchmark.leak_detection // {}' ./benchmark.json)
+    local warmup=$(echo "$settings" | jq -r '.warmup_fraction // 0.2')
+    local threshold=$(echo "$settings" | jq -r '.threshold_mb_per_min // 1')
+    local min_r2=$(echo "$settings" | jq -r '.min_r_squared // 0.8')
+    local interval=$(echo "$settings" | jq -r '.sample_interval_seconds // 1')
+
+    local rss_trend=($(memory_trend 2 "$warmup"))
+    local heap_trend=(0 0)
+    if [ "$CURRENT_METRICS" = true ]; then
+        heap_trend=($(memory_trend 3 "$warmup"))
+    fi
+
+    local leak=$(awk -v rs="${rss_trend[0]}" -v rr="${rss_trend[1]}" -v hs="${heap_trend[0]}" -v hr="${heap_trend[1]}" \
+        -v threshold="$threshold" -v min_r2="$min_r2" \
+        'BEGIN { print ((rs >= threshold && rr >= min_r2) || (hs >= threshold && hr >= min_r2)) ? "true" : "false" }')
+    local samples=$(awk '{ printf "%s[%d, %d, %d]", (NR > 1 ? ", " : ""), $1, $2, $3 }' "$MEMORY_SAMPLES")
+
+    if [ "$leak" = true ]; then
+        print_warning "Suspected memory leak in $server_name: RSS ${rss_trend[0]} MB/min, heap ${heap_trend[0]} MB/min"
+    else
+        print_status "Memory of $server_name: RSS ${rss_trend[0]} MB/min, heap ${heap_trend[0]} MB/min"
+    fi
+
+    record_framework_info "$server_name" "\"memory\": {\"interval_seconds\": $interval, \"heap_sampled\": $CURRENT_METRICS, \"samples\": [$samples], \"rss_slope_mb_per_min\": ${rss_trend[0]}, \"rss_r_squared\": ${rss_trend[1]}, \"heap_slope_mb_per_min\": ${heap_trend[0]}, \"heap_r_squared\": ${heap_trend[1]}, \"threshold_mb_per_min\": $threshold, \"suspected_leak\": $leak}"
+    rm -f "$MEMORY_SAMPLES"
+}
+
+# Function to measure graceful shutdown: loadgen sends the server SIGTERM
+# halfway through a run on / and records how the in-flight requests ended
+# and how long the process took to exit.
+run_shutdown_test() {
+    local url=$1
+    local server_pid=$2
+
+    # pprof dies with the server, so there is nothing to capture
+    CURRENT_PPROF=false
+    run_loadgen "$url" "Graceful shutdown" GET "" -shutdown-pid "$server_pid" -pipeline 1
+}
+
+# Function to warm a server up with real load on / until requests/sec and
+# P99 are stable over a sliding window (loadgen -until-stable), for at least
+# WARMUP_TIME and at most WARMUP_MAX seconds. The warm-up is recorded in
+# framework_info. With wrk, which cannot stop on stability, the warm-up is
+# WARMUP_TIME seconds of load.
+warm_up_server() {
+    local server_name=$1
+    local window=$(benchmark_setting warmup window_seconds 5)
+
+    if [ "$LOAD_GENERATOR" != "go" ]; then
+        print_status "Warming up $server_name for $WARMUP_TIME seconds..."
+        ${LOADGEN_CPUS:+taskset -c $LOADGEN_CPUS} wrk -t$THREADS -c$CONNECTIONS -d${WARMUP_TIME}s \
+            "http://localhost:$TARGET_PORT/" >/dev/null 2>&1 || true
+        return 0
+    fi
+
+    print_status "Warming up $server_name until throughput and P99 are stable (${WARMUP_TIME}-${WARMUP_MAX}s)..."
+    local output=$(mktemp)
+    if ! ${LOADGEN_CPUS:+taskset -c $LOADGEN_CPUS} "$LOADGEN_BIN" -name "Warm-up" -url "http://localhost:$TARGET_PORT/" \
+        -c "$CONNECTIONS" -t "$THREADS" -d "${WARMUP_MAX}s" -min-duration "${WARMUP_TIME}s" -until-stable "$window" \
+        -stable-rps-cv "$(benchmark_setting warmup max_rps_cv 0.05)" \
+        -stable-p99-cv "$(benchmark_setting warmup max_p99_cv 0.2)" \
+        -o "$output" 2>/dev/null; then
+        print_warning "Warm-up load failed for $server_name"
+        rm -f "$output"
+        return 0
+    fi
+
+    if command -v jq >/dev/null 2>&1; then
+        local warmup=$(jq -c '.warmup' "$output")
+        record_framework_info "$server_name" "\"warmup\": $warmup"
+        if [ "$(echo "$warmup" | jq -r '.stable')" = true ]; then
+            print_success "Warmed up after $(echo "$warmup" | jq -r '.seconds')s at $(echo "$warmup" | jq -r '.requests_per_sec | round') req/s"
+        else
+            print_warning "$server_name did not stabilize within ${WARMUP_MAX}s (requests/sec CV $(echo "$warmup" | jq -r '.rps_cv * 1000 | round / 1000'), P99 CV $(echo "$warmup" | jq -r '.p99_cv * 1000 | round / 1000'))"
+        fi
+    fi
+    rm -f "$output"
+}
+
+# Function to benchmark a server. An optional CPU profile builds a Go server
+# with profile-guided optimization.
 benchmark_server() {
     local server_name=$1
     local start_command=$2
     local server_dir=$3
+    local pgo_profile=${4:-}
 
     print_status "Starting benchmark for: $server_name"
+    CURRENT_FRAMEWORK=$server_name
 
     # Cleanup any existing processes
     cleanup_port
@@ -146,23 +876,57 @@ benchmark_server() {
     # Create temporary results file for this server
     TEMP_RESULTS=$(mktemp)
 
+    if [ -n "$SERVER_LIMITS" ] && ! create_server_cgroup "$server_name"; then
+        print_warning "Could not create a cgroup for $server_name, running it without resource limits"
+        SERVER_CGROUP=""
+    fi
+
     # Start server
     print_status "Starting $server_name server..."
+    if [[ "$start_command" == *"go"* ]]; then
+        sync_server_files "$server_dir"
+    fi
     cd "$server_dir"
 
     # Start server in background
     if [[ "$start_command" == *"go"* ]]; then
         # For Go servers, build first
+        # --profile builds in profile.go, which serves pprof on a side port,
+        # and memory sampling or --gc-telemetry metrics.go, which serves
+        # runtime/metrics
+        local tags=()
+        if [ "$PROFILE" = true ]; then
+            tags+=(benchprofile)
+        fi
+        if [ "$MEMORY_SAMPLING" = true ] || [ "$GC_TELEMETRY" = true ]; then
+            tags+=(benchmetrics)
+        fi
+        local build_flags=""
+        if [ ${#tags[@]} -gt 0 ]; then
+            build_flags="-tags $(IFS=,; echo "${tags[*]}")"
+        fi
+        if [ -n "$pgo_profile" ]; then
+            build_flags="$build_flags -pgo=$pgo_profile"
+        fi
+
         if [ -f "go.mod" ]; then
             go mod tidy
-            go build -o server .
-            ./server &
+            go build $build_flags -o server .
+            PPROF_PORT=$PPROF_PORT METRICS_PORT=$METRICS_PORT GOMAXPROCS=$SERVER_GOMAXPROCS GOGC=$SERVER_GOGC GOMEMLIMIT=$SERVER_GOMEMLIMIT ${SERVER_CGROUP:+run_in_cgroup $SERVER_CGROUP} ${SERVER_CPUS:+taskset -c $SERVER_CPUS} ./server &
         else
-            go run . &
+            PPROF_PORT=$PPROF_PORT METRICS_PORT=$METRICS_PORT GOMAXPROCS=$SERVER_GOMAXPROCS GOGC=$SERVER_GOGC GOMEMLIMIT=$SERVER_GOMEMLIMIT ${SERVER_CGROUP:+run_in_cgroup $SERVER_CGROUP} ${SERVER_CPUS:+taskset -c $SERVER_CPUS} go run $build_flags . &
         fi
     else
-        # For Node.js/Bun servers
-        eval "$start_command" &
+        # For Node.js/Bun servers. exec replaces the wrapping shell, so $!
+        # is the server itself: memory sampling and the SIGTERM of the
+        # shutdown test need its PID
+        if [ -n "$SERVER_CGROUP" ]; then
+            run_in_cgroup "$SERVER_CGROUP" ${SERVER_CPUS:+taskset -c $SERVER_CPUS} bash -c "exec $start_command" &
+        elif [ -n "$SERVER_CPUS" ]; then
+            taskset -c "$SERVER_CPUS" bash -c "exec $start_command" &
+        else
+            eval "exec $start_command" &
+        fi
     fi
 
     local server_pid=$!
@@ -170,47 +934,58 @@ benchmark_server() {
 
     # Wait for server to be ready
     if wait_for_server; then
-        # Warmup
-        print_status "Warming up server for $WARMUP_TIME seconds..."
-        for i in $(seq 1 $WARMUP_TIME); do
-            curl -s "http://localhost:$PORT/" >/dev/null 2>&1 || true
-            sleep 1
-        done
+        warm_up_server "$server_name"
+
+        CURRENT_PPROF=false
+        if [ "$PROFILE" = true ] && [[ "$start_command" == *"go"* ]]; then
+            if curl -sf "http://localhost:$PPROF_PORT/debug/pprof/" >/dev/null 2>&1; then
+                CURRENT_PPROF=true
+            else
+                print_warning "No pprof listener on port $PPROF_PORT for $server_name (missing profile.go?), skipping profiles"
+            fi
+        fi
 
-        # Run benchmarks for different endpoints
-        run_wrk "http://localhost:$PORT/" "Root endpoint"
-        run_wrk "http://localhost:$PORT/health" "Health check"
-        run_wrk "http://localhost:$PORT/user/123" "User endpoint"
-
-        # POST benchmark with payload
-        print_status "Running POST benchmark..."
-        local post_output
-        post_output=$(wrk -t$THREADS -c$CONNECTIONS -d${BENCHMARK_DURATION}s \
-            -s <(echo 'wrk.method = "POST"; wrk.body = "{\"name\":\"Test User\"}"; wrk.headers["Content-Type"] = "application/json"') \
-            "http://localhost:$PORT/users" 2>&1)
-
-        local post_rps=$(echo "$post_output" | grep "Requests/sec:" | awk '{print $2}')
-        local post_latency=$(echo "$post_output" | grep "Latency" | head -1 | awk '{print $2}')
-
-        # Escape the POST output properly for JSON
-        local escaped_post_output=$(echo "$post_output" | sed 's/\\/\\\\/g; s/"/\\"/g' | awk '{printf "%s\\n", $0}' | sed 's/\\n$//')
-
-        cat << EOF >> "$TEMP_RESULTS"
-    {
-      "endpoint": "POST users",
-      "url": "http://localhost:$PORT/users",
-      "requests_per_sec": "$post_rps",
-      "avg_latency": "$post_latency",
-      "transfer_per_sec": "",
-      "latency_percentiles": {
-        "50%": "",
-        "75%": "",
-        "90%": "",
-        "99%": ""
-      },
-      "raw_output": "$escaped_post_output"
-    },
-EOF
+        CURRENT_METRICS=false
+        if [ "$MEMORY_SAMPLING" = true ] || [ "$GC_TELEMETRY" = true ]; then
+            if [[ "$start_command" == *"go"* ]]; then
+                if curl -sf "http://localhost:$METRICS_PORT/debug/metrics" >/dev/null 2>&1; then
+                    CURRENT_METRICS=true
+                else
+                    print_warning "No runtime metrics listener on port $METRICS_PORT for $server_name (missing metrics.go?), no heap or GC metrics"
+                fi
+            fi
+        fi
+        # GC matrix entries are sampled too: their RSS is the price of the
+        # GOGC/GOMEMLIMIT setting
+        if [ "$MEMORY_SAMPLING" = true ] || [ -n "$SERVER_GOGC" ]; then
+            start_memory_sampler "$server_pid"
+        fi
+
+        if [ "$LOAD_GENERATOR" = "go" ]; then
+            run_loadgen "http://localhost:$TARGET_PORT/" "Root endpoint"
+            run_loadgen "http://localhost:$TARGET_PORT/health" "Health check"
+            run_loadgen "http://localhost:$TARGET_PORT/user/123" "User endpoint"
+            run_loadgen "http://localhost:$TARGET_PORT/users" "POST users" POST '{"name":"Test User"}'
+        else
+            # Run benchmarks for different endpoints
+            run_wrk "http://localhost:$TARGET_PORT/" "Root endpoint"
+            run_wrk "http://localhost:$TARGET_PORT/health" "Health check"
+            run_wrk "http://localhost:$TARGET_PORT/user/123" "User endpoint"
+            run_wrk_post "http://localhost:$TARGET_PORT/users" "POST users"
+        fi
+
+        if [ "$SLOW_CLIENTS" -gt 0 ]; then
+            run_slow_client_test "http://localhost:$TARGET_PORT/"
+        fi
+
+        if [ -n "$MEMORY_SAMPLER_PID" ]; then
+            stop_memory_sampler "$server_name"
+        fi
+
+        # Last: the server is gone afterwards
+        if [ "$SHUTDOWN_TEST" = true ]; then
+            run_shutdown_test "http://localhost:$TARGET_PORT/" "$server_pid"
+        fi
 
         print_success "All benchmarks completed for $server_name"
     else
@@ -223,9 +998,16 @@ EOF
     cleanup_port
     sleep 2
 
+    if [ -n "$SERVER_CGROUP" ]; then
+        record_cgroup_stats "$server_name"
+        remove_server_cgroup
+    fi
+
     # Process results - remove trailing comma and wrap in proper array format
     if [ -s "$TEMP_RESULTS" ]; then
-        local results_content=$(cat "$TEMP_RESULTS" | sed '$ s/,$//')
+        # Results are stored one server per line, so fold the objects onto a
+        # single line (JSON strings never contain raw newlines)
+        local results_content=$(cat "$TEMP_RESULTS" | sed '$ s/,$//' | tr '\n' ' ')
         echo "$server_name|$results_content" >> "$RESULTS_FILE.tmp"
     else
         print_warning "No results collected for $server_name"
@@ -236,9 +1018,26 @@ EOF
 
 # Main execution
 main() {
+    # Keep pipelined runs in their own files so they are never mistaken for
+    # (or mixed with) one-request-per-connection numbers.
+    # The same goes for runs behind an emulated network
+    local suffix=""
+    if [ "$PIPELINE_DEPTH" -gt 1 ]; then
+        suffix="${suffix}_pipeline${PIPELINE_DEPTH}"
+    fi
+    if [ "$NETWORK_PROFILE" != "loopback" ]; then
+        suffix="${suffix}_net-${NETWORK_PROFILE}"
+    fi
+    RESULTS_FILE="$RESULTS_DIR/benchmark_${TIMESTAMP}${suffix}.json"
+    DISTRIBUTION_DIR="${RESULTS_FILE%.json}_distributions"
+    PROFILE_DIR="${RESULTS_FILE%.json}"
+
     print_status "Starting comprehensive benchmark suite"
     print_status "Results will be saved to: $RESULTS_FILE"
 
+    setup_cpu_isolation
+    check_host
+
     # Initialize results file
     echo "{" > "$RESULTS_FILE"
     echo "  \"timestamp\": \"$(date -Iseconds)\"," >> "$RESULTS_FILE"
@@ -246,13 +1045,58 @@ main() {
     echo "    \"duration\": $BENCHMARK_DURATION," >> "$RESULTS_FILE"
     echo "    \"connections\": $CONNECTIONS," >> "$RESULTS_FILE"
     echo "    \"threads\": $THREADS," >> "$RESULTS_FILE"
-    echo "    \"warmup_time\": $WARMUP_TIME" >> "$RESULTS_FILE"
+    echo "    \"warmup_time\": $WARMUP_TIME," >> "$RESULTS_FILE"
+    # wrk warms up for a fixed time, recorded without a cap
+    if [ "$LOAD_GENERATOR" = "go" ]; then
+        echo "    \"warmup_max\": $WARMUP_MAX," >> "$RESULTS_FILE"
+    else
+        echo "    \"warmup_max\": 0," >> "$RESULTS_FILE"
+    fi
+    echo "    \"load_generator\": \"$LOAD_GENERATOR\"," >> "$RESULTS_FILE"
+    echo "    \"pipeline_depth\": $PIPELINE_DEPTH," >> "$RESULTS_FILE"
+    echo "    \"slow_clients\": $SLOW_CLIENTS," >> "$RESULTS_FILE"
+    echo "    \"trace_rate\": $TRACE_RATE," >> "$RESULTS_FILE"
+    echo "    \"network_profile\": \"$NETWORK_PROFILE\"," >> "$RESULTS_FILE"
+    echo "    \"profiling\": $PROFILE," >> "$RESULTS_FILE"
+    echo "    \"pgo\": $PGO," >> "$RESULTS_FILE"
+    echo "    \"cpu_sweep\": $CPU_SWEEP," >> "$RESULTS_FILE"
+    echo "    \"cold_starts\": $COLD_STARTS," >> "$RESULTS_FILE"
+    echo "    \"footprint\": $FOOTPRINT," >> "$RESULTS_FILE"
+    echo "    \"shutdown_test\": $SHUTDOWN_TEST," >> "$RESULTS_FILE"
+    echo "    \"load_profile\": \"$LOAD_PROFILE\"," >> "$RESULTS_FILE"
+    echo "    \"memory_sampling\": $MEMORY_SAMPLING," >> "$RESULTS_FILE"
+    echo "    \"gc_telemetry\": $GC_TELEMETRY," >> "$RESULTS_FILE"
+    echo "    \"gc_matrix\": $GC_MATRIX," >> "$RESULTS_FILE"
+    echo "    \"host\": $HOST_JSON," >> "$RESULTS_FILE"
+    echo "    \"cpu_isolation\": $ISOLATE_CPUS," >> "$RESULTS_FILE"
+    echo "    \"load_generator_cpus\": \"$LOADGEN_CPUS\"," >> "$RESULTS_FILE"
+    echo "    \"server_cpus\": \"$ISOLATED_SERVER_CPUS\"," >> "$RESULTS_FILE"
+    echo "    \"resource_profile\": \"$RESOURCE_PROFILE\"" >> "$RESULTS_FILE"
     echo "  }," >> "$RESULTS_FILE"
     echo "  \"results\": {" >> "$RESULTS_FILE"
 
     # Initialize temporary results file
     touch "$RESULTS_FILE.tmp"
 
+    if [ "$LOAD_GENERATOR" = "go" ] || [ "$SLOW_CLIENTS" -gt 0 ] || [ "$SHUTDOWN_TEST" = true ]; then
+        build_loadgen
+    fi
+
+    if [ "$COLD_STARTS" -gt 0 ]; then
+        print_status "Building cold-start tool..."
+        build_go_tool coldstart "$COLDSTART_BIN"
+    fi
+
+    if [ "$GC_TELEMETRY" = true ]; then
+        print_status "Building GC telemetry sampler..."
+        build_go_tool gcwatch "$GCWATCH_BIN"
+    fi
+
+    calibrate_load_generator
+
+    start_network_proxy
+    trap 'stop_network_proxy; remove_cgroups' EXIT
+
     # Auto-discover servers from configuration
     discover_and_benchmark_servers
 
@@ -274,17 +1118,55 @@ main() {
         done < "$RESULTS_FILE.tmp"
 
         echo "" >> "$RESULTS_FILE"
-        echo "  }" >> "$RESULTS_FILE"
-        echo "}" >> "$RESULTS_FILE"
+        echo -n "  }" >> "$RESULTS_FILE"
     else
         # No results found, create empty results structure
-        echo "  }" >> "$RESULTS_FILE"
-        echo "}" >> "$RESULTS_FILE"
+        echo -n "  }" >> "$RESULTS_FILE"
         print_warning "No benchmark results were collected"
     fi
 
+    # Metadata of entries (e.g. the base of go-vanilla+pgo), keyed like
+    # results; the fields recorded for one entry are merged into one object
+    if [ -s "$RESULTS_FILE.info" ]; then
+        echo "," >> "$RESULTS_FILE"
+        echo "  \"framework_info\": {" >> "$RESULTS_FILE"
+        local first=true
+        while IFS= read -r server_name; do
+            if [ "$first" = true ]; then
+                first=false
+            else
+                echo "," >> "$RESULTS_FILE"
+            fi
+            local server_info=$(awk -v name="$server_name" '
+                index($0, name "|") == 1 { printf "%s%s", (n++ ? ", " : ""), substr($0, length(name) + 2) }
+            ' "$RESULTS_FILE.info")
+            echo -n "    \"$server_name\": {$server_info}" >> "$RESULTS_FILE"
+        done < <(cut -d'|' -f1 "$RESULTS_FILE.info" | awk '!seen[$0]++')
+        echo "" >> "$RESULTS_FILE"
+        echo -n "  }" >> "$RESULTS_FILE"
+    fi
+
+    # The ceiling of the load generator, for the report to mark results that
+    # are close to it
+    if [ -n "$LOADGEN_CEILING" ]; then
+        echo "," >> "$RESULTS_FILE"
+        echo -n "  \"loadgen_calibration\": {\"requests_per_sec\": $LOADGEN_CEILING, \"loadgen_cpu_percent\": ${LOADGEN_CEILING_CPU:-null}, \"bound_margin_percent\": $LOADGEN_BOUND_MARGIN, \"cpu_saturation_percent\": $LOADGEN_CPU_SATURATION}" >> "$RESULTS_FILE"
+    fi
+
+    # The order the servers actually ran in, and how to reproduce it
+    local sequence=""
+    local name
+    for name in "${RUN_SEQUENCE[@]}"; do
+        sequence="${sequence:+$sequence, }\"$name\""
+    done
+    echo "," >> "$RESULTS_FILE"
+    echo -n "  \"run_order\": {\"seed\": $RUN_SEED, \"shuffled\": $SHUFFLE, \"interleaved\": $INTERLEAVE, \"iterations\": $ITERATIONS, \"cooldown_seconds\": $COOLDOWN, \"sequence\": [$sequence]}" >> "$RESULTS_FILE"
+    echo "" >> "$RESULTS_FILE"
+    echo "}" >> "$RESULTS_FILE"
+
     # Cleanup
-    rm -f "$RESULTS_FILE.tmp"
+    rm -f "$RESULTS_FILE.tmp" "$RESULTS_FILE.info"
+    stop_network_proxy
 
     print_success "Benchmark suite completed!"
     print_status "Results saved to: $RESULTS_FILE"
@@ -309,18 +1191,521 @@ main() {
     fi
 }
 
-# Auto-discover and benchmark servers from configuration
-discover_and_benchmark_servers() {
-    local config_file="./benchmark.json"
+# Function to record metadata about a framework entry of the results, such
+# as the base framework and settings of a variant. Takes JSON object fields
+# (without braces); fields recorded for the same entry are merged.
+record_framework_info() {
+    local name=$1
+    local fields=$2
+
+    echo "$name|$fields" >> "$RESULTS_FILE.info"
+}
+
+# Function to drive one endpoint with the configured load generator without
+# recording results, for passes that only need the server under load
+drive_load() {
+    local url=$1
+    local method=${2:-GET}
+    local body=${3:-}
+
+    if [ "$LOAD_GENERATOR" = "go" ]; then
+        local args=(-url "$url" -method "$method" -c "$CONNECTIONS" -t "$THREADS"
+            -d "${BENCHMARK_DURATION}s" -pipeline "$PIPELINE_DEPTH" -o /dev/null)
+        if [ -n "$body" ]; then
+            args+=(-body "$body" -H "Content-Type: application/json")
+        fi
+        ${LOADGEN_CPUS:+taskset -c $LOADGEN_CPUS} "$LOADGEN_BIN" "${args[@]}" 2>/dev/null || true
+    elif [ -n "$body" ]; then
+        ${LOADGEN_CPUS:+taskset -c $LOADGEN_CPUS} wrk -t$THREADS -c$CONNECTIONS -d${BENCHMARK_DURATION}s \
+            -s <(echo "wrk.method = \"$method\"; wrk.body = '$body'; wrk.headers[\"Content-Type\"] = \"application/json\"") \
+            "$url" >/dev/null 2>&1 || true
+    else
+        ${LOADGEN_CPUS:+taskset -c $LOADGEN_CPUS} wrk -t$THREADS -c$CONNECTIONS -d${BENCHMARK_DURATION}s "$url" >/dev/null 2>&1 || true
+    fi
+}
+
+# Function to collect the CPU profile a PGO build is optimized with. The
+# server runs with pprof compiled in (profile.go) under the benchmark's
+# endpoint workload; the per-endpoint profiles are merged into one.
+collect_pgo_profile() {
+    local server_name=$1
+    local server_dir=$2
+    local profile_file=$3
+
+    print_status "Collecting PGO profile for $server_name..."
+    cleanup_port
+    mkdir -p "$(dirname "$profile_file")"
+
+    sync_server_files "$server_dir"
+    cd "$server_dir"
+    go build -tags benchprofile -o server .
+    PPROF_PORT=$PPROF_PORT ./server &
+    local server_pid=$!
+    cd - > /dev/null
+
+    local parts=()
+    if wait_for_server && curl -sf "http://localhost:$PPROF_PORT/debug/pprof/" >/dev/null 2>&1; then
+        local base="http://localhost:$PPROF_PORT/debug/pprof/profile?seconds=$BENCHMARK_DURATION"
+        local endpoint
+        for endpoint in / /health /user/123 /users; do
+            local part="$profile_file.$(( ${#parts[@]} + 1 ))"
+            curl -s -o "$part" "$base" &
+            local curl_pid=$!
+            if [ "$endpoint" = "/users" ]; then
+                drive_load "http://localhost:$TARGET_PORT$endpoint" POST '{"name":"Test User"}'
+            else
+                drive_load "http://localhost:$TARGET_PORT$endpoint"
+            fi
+            wait $curl_pid || true
+            if [ -s "$part" ]; then
+                parts+=("$part")
+            fi
+        done
+    else
+        print_warning "No pprof listener for $server_name (missing profile.go?)"
+    fi
+
+    kill $server_pid 2>/dev/null || true
+    cleanup_port
+    sleep 2
+
+    if [ ${#parts[@]} -eq 0 ]; then
+        return 1
+    fi
+    go tool pprof -proto "${parts[@]}" > "$profile_file" 2>/dev/null || return 1
+    rm -f "${parts[@]}"
+    print_success "PGO profile saved to: $profile_file"
+}
+
+# Function to list the CPUs this script may run on, one id per line
+available_cpus() {
+    taskset -cp $$ | sed 's/.*: //' | tr ',' '\n' | \
+        awk -F- '{ if (NF == 2) { for (i = $1; i <= $2; i++) print i } else print $1 }'
+}
+
+# Function to list the CPUs servers may be pinned to: the server set when
+# CPUs are isolated, otherwise every available CPU
+server_cpu_pool() {
+    if [ -n "$ISOLATED_SERVER_CPUS" ]; then
+        echo "$ISOLATED_SERVER_CPUS" | tr ',' '\n'
+    else
+        available_cpus
+    fi
+}
+
+# Function to split the available CPUs into disjoint sets for the load
+# generator and the servers. The load generator gets one CPU per thread, up
+# to half of the machine, and the servers get the rest.
+setup_cpu_isolation() {
+    if [ "$ISOLATE_CPUS" != true ]; then
+        return 0
+    fi
+
+    local cpus=($(available_cpus))
+    local total=${#cpus[@]}
+    if [ "$total" -lt 2 ]; then
+        print_warning "CPU isolation needs at least 2 CPUs but only $total is available; load generator and server will share it"
+        ISOLATE_CPUS=false
+        return 0
+    fi
+
+    local loadgen_count=$THREADS
+    if [ "$loadgen_count" -gt $((total / 2)) ]; then
+        loadgen_count=$((total / 2))
+    fi
+    if [ "$loadgen_count" -lt 1 ]; then
+        loadgen_count=1
+    fi
+
+    LOADGEN_CPUS=$(printf '%s\n' "${cpus[@]:0:loadgen_count}" | paste -sd, -)
+    ISOLATED_SERVER_CPUS=$(printf '%s\n' "${cpus[@]:loadgen_count}" | paste -sd, -)
+    SERVER_CPUS=$ISOLATED_SERVER_CPUS
+
+    if [ "$total" -lt 4 ]; then
+        print_warning "Only $total CPUs available: isolating them leaves both the load generator and the server short of cores"
+    fi
+    if [ "$loadgen_count" -lt "$THREADS" ]; then
+        print_warning "The load generator runs $THREADS threads on $loadgen_count CPU(s); lower --threads or use a bigger machine"
+    fi
+    print_status "CPU isolation: load generator on CPUs $LOADGEN_CPUS, servers on CPUs $ISOLATED_SERVER_CPUS"
+}
+
+# Function to list the CPU counts of the scaling sweep: 1, 2, 4 and all
+# available CPUs, without counts the machine cannot provide
+cpu_sweep_counts() {
+    local total=$(server_cpu_pool | wc -l)
+    local count
+    for count in 1 2 4 "$total"; do
+        if [ "$count" -le "$total" ]; then
+            echo "$count"
+        fi
+    done | sort -nu
+}
+
+# Function to list the values of one GC matrix dimension (gogc or
+# gomemlimit) for a framework: its own gc_matrix, else the one in
+# benchmark.json
+gc_matrix_values() {
+    local framework=$1
+    local dimension=$2
+
+    jq -r "(.frameworks[\"$framework\"].gc_matrix.$dimension // .benchmark.gc_matrix.$dimension // [])[]" ./benchmark.json
+}
+
+# Function to benchmark one configured framework, followed by its variants
+benchmark_framework() {
+    local server_name=$1
+    local start_command=$2
+    local server_dir=$3
+
+    # Variants run under the same limits as their base framework
+    SERVER_LIMITS=$(resource_limits_for "$server_name")
+    if [ -n "$SERVER_LIMITS" ]; then
+        print_status "Resource limits for $server_name: ${SERVER_LIMITS% *} CPU(s), ${SERVER_LIMITS#* } memory"
+    fi
+
+    benchmark_server "$server_name" "$start_command" "$server_dir"
+    if is_baseline "$server_name"; then
+        record_framework_info "$server_name" '"baseline": true'
+    fi
+
+    if [ "$COLD_STARTS" -gt 0 ]; then
+        measure_cold_start "$server_name" "$start_command" "$server_dir"
+    fi
+
+    if [ "$FOOTPRINT" = true ]; then
+        measure_footprint "$server_name" "$start_command" "$server_dir"
+    fi
+
+    if [ "$PGO" = true ] && [ -f "$server_dir/go.mod" ]; then
+        # Built with an absolute path: the build runs inside the server directory
+        local profile_file="$(pwd)/${PROFILE_DIR#./}/$server_name/pgo.cpu.pprof"
+        if collect_pgo_profile "$server_name" "$server_dir" "$profile_file"; then
+            benchmark_server "$server_name+pgo" "$start_command" "$server_dir" "$profile_file"
+            record_framework_info "$server_name+pgo" "\"base\": \"$server_name\", \"variant\": \"pgo\", \"pgo_profile\": \"${PROFILE_DIR}/$server_name/pgo.cpu.pprof\""
+        else
+            print_warning "Could not collect a PGO profile for $server_name, skipping the PGO variant"
+        fi
+    fi
+
+    if [ "$CPU_SWEEP" = true ]; then
+        local count
+        for count in $(cpu_sweep_counts); do
+            SERVER_CPUS=$(server_cpu_pool | head -n "$count" | paste -sd, -)
+            # Go would otherwise size its scheduler to every core of the machine
+            if [[ "$start_command" == *"go"* ]]; then
+                SERVER_GOMAXPROCS=$count
+            fi
+
+            print_status "Scaling sweep: $server_name on $count CPU(s) ($SERVER_CPUS)"
+            benchmark_server "$server_name@${count}cpu" "$start_command" "$server_dir"
+            record_framework_info "$server_name@${count}cpu" "\"base\": \"$server_name\", \"variant\": \"cpus\", \"cpus\": $count, \"cpu_list\": \"$SERVER_CPUS\""
+        done
+        SERVER_CPUS=$ISOLATED_SERVER_CPUS
+        SERVER_GOMAXPROCS=""
+    fi
+
+    if [ "$GC_MATRIX" = true ] && [ -f "$server_dir/go.mod" ]; then
+        local gogc gomemlimit
+        for gomemlimit in $(gc_matrix_values "$server_name" gomemlimit); do
+            for gogc in $(gc_matrix_values "$server_name" gogc); do
+                local variant="$server_name@gogc$gogc"
+                if [ "$gomemlimit" != off ]; then
+                    variant="$variant-mem$gomemlimit"
+                fi
+                SERVER_GOGC=$gogc
+                SERVER_GOMEMLIMIT=$gomemlimit
+
+                print_status "GC matrix: $server_name with GOGC=$gogc GOMEMLIMIT=$gomemlimit"
+                benchmark_server "$variant" "$start_command" "$server_dir"
+                record_framework_info "$variant" "\"base\": \"$server_name\", \"variant\": \"gc\", \"gogc\": \"$gogc\", \"gomemlimit\": \"$gomemlimit\""
+            done
+        done
+        SERVER_GOGC=""
+        SERVER_GOMEMLIMIT=""
+    fi
+
+    SERVER_LIMITS=""
+}
+
+# Function to measure the cold start of a server (scripts/coldstart): spawn to
+# first successful /health response, polled every millisecond, and the
+# latencies of the first 100 requests, over COLD_STARTS starts. Go servers
+# start from the binary benchmark_server built, so compilation is not counted.
+measure_cold_start() {
+    local server_name=$1
+    local start_command=$2
+    local server_dir=$3
+
+    local command=(bash -c "$start_command")
+    if [[ "$start_command" == *"go"* ]] && [ -x "$server_dir/server" ]; then
+        command=(./server)
+    fi
+
+    print_status "Measuring $COLD_STARTS cold starts of $server_name..."
+    cleanup_port
+
+    local output=$(mktemp)
+    if ${LOADGEN_CPUS:+taskset -c $LOADGEN_CPUS} "$COLDSTART_BIN" -runs "$COLD_STARTS" -dir "$server_dir" \
+        -url "http://localhost:$PORT/health" -request-url "http://localhost:$PORT/" -o "$output" \
+        -- ${SERVER_CPUS:+taskset -c $SERVER_CPUS} "${command[@]}"; then
+        record_framework_info "$server_name" "\"cold_start\": $(cat "$output")"
+        print_success "Cold start of $server_name: median $(jq -r '.startup_median_ms' "$output" 2>/dev/null)ms, first request $(jq -r '.first_request_median_us' "$output" 2>/dev/null)us"
+    else
+        print_warning "Could not measure the cold start of $server_name"
+    fi
+
+    rm -f "$output"
+    cleanup_port
+}
+
+# Function to print the current time in milliseconds
+now_ms() {
+    echo $(( $(date +%s%N) / 1000000 ))
+}
+
+# Function to record the build footprint of a server. Go servers: build time
+# from an empty build cache (modules are already downloaded), binary size
+# with and without symbols, and module counts. Bun servers: install time,
+# node_modules size and package counts.
+measure_footprint() {
+    local server_name=$1
+    local start_command=$2
+    local server_dir=$3
+
+    print_status "Measuring build footprint of $server_name..."
+    cd "$server_dir"
+
+    local footprint=""
+    if [ -f "go.mod" ]; then
+        local cache=$(mktemp -d)
+        local binary=$(mktemp)
+        local stripped=$(mktemp)
+
+        # Cold: from an empty build cache, mostly compiling the standard
+        # library, which is the same for every server
+        local started=$(now_ms)
+        local cold_build_time=""
+        if GOCACHE="$cache" go build -o "$binary" .; then
+            cold_build_time=$(( $(now_ms) - started ))
+        fi
+
+        # Build time: with the standard library packages the server uses
+        # already compiled, so only the framework, its modules and the
+        # server itself are built
+        rm -rf "$cache" && mkdir -p "$cache"
+        local build_time=""
+        if [ -n "$cold_build_time" ] && GOCACHE="$cache" go build $(go list -deps -f '{{if .Standard}}{{.ImportPath}}{{end}}' .); then
+            started=$(now_ms)
+            if GOCACHE="$cache" go build -o "$binary" .; then
+                build_time=$(( $(now_ms) - started ))
+            fi
+        fi
+        if [ -n "$build_time" ] && go build -ldflags="-s -w" -o "$stripped" .; then
+            local modules=$(go list -m all | tail -n +2 | wc -l)
+            local direct=$(go list -m -f '{{if not .Indirect}}{{.Path}}{{end}}' all | tail -n +2 | grep -c . || true)
+            footprint="{\"runtime\": \"go\", \"build_time_ms\": $build_time, \"cold_build_time_ms\": $cold_build_time, \"binary_bytes\": $(wc -c < "$binary"), \"stripped_bytes\": $(wc -c < "$stripped"), \"modules\": $modules, \"direct_modules\": $direct}"
+        fi
+
+        rm -rf "$cache" "$binary" "$stripped"
+    elif [ -f "package.json" ] && [[ "$start_command" == *"bun"* ]]; then
+        # --force reinstalls every package instead of trusting node_modules
+        local started=$(now_ms)
+        if bun install --force >/dev/null 2>&1; then
+            local install_time=$(( $(now_ms) - started ))
+            local node_modules_kb=0
+            local packages=0
+            if [ -d "node_modules" ]; then
+                node_modules_kb=$(du -sk node_modules | cut -f1)
+                # Top-level packages, counting scoped ones (@scope/name) individually
+                packages=$(ls -d node_modules/[!@.]*/ node_modules/@*/*/ 2>/dev/null | wc -l)
+            fi
+            local direct=0
+            if command -v jq >/dev/null 2>&1; then
+                direct=$(jq '.dependencies // {} | length' package.json)
+            fi
+            footprint="{\"runtime\": \"bun\", \"install_time_ms\": $install_time, \"node_modules_bytes\": $((node_modules_kb * 1024)), \"packages\": $packages, \"direct_dependencies\": $direct}"
+        fi
+    fi
+
+    cd - > /dev/null
+
+    if [ -n "$footprint" ]; then
+        record_framework_info "$server_name" "\"footprint\": $footprint"
+        print_success "Build footprint of $server_name recorded"
+    else
+        print_warning "Could not measure the build footprint of $server_name"
+    fi
+}
+
+# Function to print the resource limits of a framework as "<cpus> <memory>"
+# ("max" = unlimited): its own "resource_limits" in benchmark.json, else the
+# --resource-profile. Prints nothing when the framework runs unlimited.
+resource_limits_for() {
+    local framework=$1
+    local config_file="./benchmark.json"
+
+    if ! command -v jq >/dev/null 2>&1 || [ ! -f "$config_file" ]; then
+        return 0
+    fi
+
+    local limits=$(jq -c ".frameworks[\"$framework\"].resource_limits // empty" "$config_file")
+    if [ -z "$limits" ] && [ -n "$RESOURCE_PROFILE" ]; then
+        limits=$(jq -c ".benchmark.resource_profiles[\"$RESOURCE_PROFILE\"] // empty" "$config_file")
+    fi
+    if [ -n "$limits" ]; then
+        echo "$limits" | jq -r '"\(.cpus // "max") \(.memory // "max")"'
+    fi
+}
+
+# Function to check whether a framework is the baseline ("baseline": true in
+# benchmark.json) the report expresses every framework's throughput against
+is_baseline() {
+    local framework=$1
+    local config_file="./benchmark.json"
+
+    if ! command -v jq >/dev/null 2>&1 || [ ! -f "$config_file" ]; then
+        return 1
+    fi
+    [ "$(jq -r ".frameworks[\"$framework\"].baseline // false" "$config_file")" = true ]
+}
+
+# Function to prepare the parent cgroup of the per-server cgroups, with the
+# cpu and memory controllers delegated to its children
+setup_cgroups() {
+    local root=$(awk '$3 == "cgroup2" { print $2; exit }' /proc/mounts)
+    if [ -z "$root" ]; then
+        print_warning "Resource limits need a cgroup v2 hierarchy, but none is mounted"
+        return 1
+    fi
+
+    local controller
+    for controller in cpu memory; do
+        if ! grep -qw "$controller" "$root/cgroup.controllers"; then
+            print_warning "Resource limits need the $controller controller, which cgroup v2 at $root does not provide"
+            return 1
+        fi
+        if ! grep -qw "$controller" "$root/cgroup.subtree_control" && \
+            ! echo "+$controller" > "$root/cgroup.subtree_control" 2>/dev/null; then
+            print_warning "Could not enable the $controller controller in $root (not root?)"
+            return 1
+        fi
+    done
+
+    CGROUP_PARENT="$root/webbench_$TIMESTAMP"
+    mkdir -p "$CGROUP_PARENT" && echo "+cpu +memory" > "$CGROUP_PARENT/cgroup.subtree_control" || {
+        rmdir "$CGROUP_PARENT" 2>/dev/null || true
+        CGROUP_PARENT=""
+        return 1
+    }
+}
+
+# Function to create the cgroup a server runs in, limited to SERVER_LIMITS
+create_server_cgroup() {
+    local server_name=$1
+    local cpus=${SERVER_LIMITS% *}
+    local memory=${SERVER_LIMITS#* }
+
+    if [ -z "$CGROUP_PARENT" ]; then
+        setup_cgroups || return 1
+    fi
+
+    SERVER_CGROUP="$CGROUP_PARENT/$(echo "$server_name" | tr -c 'A-Za-z0-9_.\n-' '_')"
+    mkdir -p "$SERVER_CGROUP" || return 1
+
+    # cpu.max is "<quota> <period>": 1.5 CPUs get 150ms of CPU time per 100ms
+    if [ "$cpus" != "max" ]; then
+        echo "$(awk -v cpus="$cpus" 'BEGIN { printf "%d", cpus * 100000 }') 100000" > "$SERVER_CGROUP/cpu.max" || return 1
+    fi
+    echo "$memory" > "$SERVER_CGROUP/memory.max" || return 1
+    # Servers hitting the memory limit should be OOM killed, not swap
+    if [ -f "$SERVER_CGROUP/memory.swap.max" ]; then
+        echo 0 > "$SERVER_CGROUP/memory.swap.max" || true
+    fi
+}
+
+# Function to run a command inside a cgroup. The subshell moves itself in
+# before exec, so the command and everything it starts are limited.
+run_in_cgroup() {
+    local cgroup=$1
+    shift
+
+    echo $BASHPID > "$cgroup/cgroup.procs"
+    exec "$@"
+}
+
+# Function to print one "key value" statistic of a cgroup file, 0 if absent
+cgroup_stat() {
+    local file=$1
+    local key=$2
+
+    awk -v key="$key" '$1 == key { print $2; found = 1 } END { if (!found) print 0 }' "$file" 2>/dev/null || echo 0
+}
+
+# Function to record the CPU throttling and OOM events of a server's cgroup.
+# The counters survive the server, so OOM kills during the run are counted.
+record_cgroup_stats() {
+    local server_name=$1
+    local cpus=${SERVER_LIMITS% *}
+    local memory=${SERVER_LIMITS#* }
+    local nr_periods=$(cgroup_stat "$SERVER_CGROUP/cpu.stat" nr_periods)
+    local nr_throttled=$(cgroup_stat "$SERVER_CGROUP/cpu.stat" nr_throttled)
+    local throttled_usec=$(cgroup_stat "$SERVER_CGROUP/cpu.stat" throttled_usec)
+    local oom=$(cgroup_stat "$SERVER_CGROUP/memory.events" oom)
+    local oom_kill=$(cgroup_stat "$SERVER_CGROUP/memory.events" oom_kill)
+    local memory_peak=$(cat "$SERVER_CGROUP/memory.peak" 2>/dev/null || echo 0)
+
+    if [ "$oom_kill" -gt 0 ]; then
+        print_warning "$server_name was OOM killed $oom_kill time(s) under its $memory memory limit"
+    fi
+    if [ "$nr_throttled" -gt 0 ]; then
+        print_status "$server_name was throttled in $nr_throttled of $nr_periods CPU periods ($((throttled_usec / 1000))ms)"
+    fi
+
+    record_framework_info "$server_name" "\"resource_limits\": {\"cpus\": \"$cpus\", \"memory\": \"$memory\", \"nr_periods\": $nr_periods, \"nr_throttled\": $nr_throttled, \"throttled_usec\": $throttled_usec, \"oom\": $oom, \"oom_kill\": $oom_kill, \"memory_peak_bytes\": $memory_peak}"
+}
+
+# Function to kill whatever is left in a server's cgroup and remove it
+remove_server_cgroup() {
+    if [ -f "$SERVER_CGROUP/cgroup.kill" ]; then
+        echo 1 > "$SERVER_CGROUP/cgroup.kill" 2>/dev/null || true
+    fi
+
+    local attempt
+    for attempt in 1 2 3 4 5 6 7 8 9 10; do
+        rmdir "$SERVER_CGROUP" 2>/dev/null && break
+        sleep 0.2
+    done
+    SERVER_CGROUP=""
+}
+
+remove_cgroups() {
+    if [ -n "$SERVER_CGROUP" ]; then
+        remove_server_cgroup
+    fi
+    if [ -n "$CGROUP_PARENT" ]; then
+        rmdir "$CGROUP_PARENT" 2>/dev/null || true
+        CGROUP_PARENT=""
+    fi
+}
+
+# Function to add a framework to the run queue
+queue_framework() {
+    FRAMEWORK_QUEUE+=("$1|$2|$3")
+}
+
+# Auto-discover servers from configuration and queue them
+discover_servers() {
+    local config_file="./benchmark.json"
 
     if [ ! -f "$config_file" ]; then
         print_error "Configuration file not found: $config_file"
         print_status "Falling back to default servers..."
         # Fallback to hardcoded servers
-        benchmark_server "go-vanilla" "go run ." "./servers/go-vanilla"
-        benchmark_server "go-fiber" "go run ." "./servers/go-fiber"
-        benchmark_server "bun-vanilla" "bun run server.ts" "./servers/bun-vanilla"
-        benchmark_server "hono-bun" "bun run server.ts" "./servers/hono-bun"
+        queue_framework "go-vanilla" "go run ." "./servers/go-vanilla"
+        queue_framework "go-fiber" "go run ." "./servers/go-fiber"
+        queue_framework "bun-vanilla" "bun run server.ts" "./servers/bun-vanilla"
+        queue_framework "hono-bun" "bun run server.ts" "./servers/hono-bun"
         return
     fi
 
@@ -332,10 +1717,10 @@ discover_and_benchmark_servers() {
 
         if [ -z "$frameworks" ]; then
             print_warning "No frameworks found in configuration. Using defaults."
-            benchmark_server "go-vanilla" "go run ." "./servers/go-vanilla"
-            benchmark_server "go-fiber" "go run ." "./servers/go-fiber"
-            benchmark_server "bun-vanilla" "bun run server.ts" "./servers/bun-vanilla"
-            benchmark_server "hono-bun" "bun run server.ts" "./servers/hono-bun"
+            queue_framework "go-vanilla" "go run ." "./servers/go-vanilla"
+            queue_framework "go-fiber" "go run ." "./servers/go-fiber"
+            queue_framework "bun-vanilla" "bun run server.ts" "./servers/bun-vanilla"
+            queue_framework "hono-bun" "bun run server.ts" "./servers/hono-bun"
             return
         fi
 
@@ -346,8 +1731,7 @@ discover_and_benchmark_servers() {
 
                 # Validate the framework configuration
                 if [ "$start_cmd" != "null" ] && [ "$directory" != "null" ] && [ -d "$directory" ]; then
-                    print_status "Found framework: $framework"
-                    benchmark_server "$framework" "$start_cmd" "$directory"
+                    queue_framework "$framework" "$start_cmd" "$directory"
                 else
                     print_warning "Skipping $framework: invalid configuration or missing directory"
                 fi
@@ -373,8 +1757,7 @@ discover_and_benchmark_servers() {
                 fi
 
                 if [ -n "$start_cmd" ]; then
-                    print_status "Auto-detected framework: $server_name"
-                    benchmark_server "$server_name" "$start_cmd" "$server_dir"
+                    queue_framework "$server_name" "$start_cmd" "$server_dir"
                 else
                     print_warning "Could not determine start command for: $server_name"
                 fi
@@ -383,13 +1766,100 @@ discover_and_benchmark_servers() {
     fi
 }
 
+# Function to advance the seeded run-order generator: a 31-bit LCG, so a
+# seed gives the same order on any machine and shell
+next_random() {
+    RNG_STATE=$(( (RNG_STATE * 1103515245 + 12345) % 2147483648 ))
+}
+
+# Function to shuffle the run queue in place (Fisher-Yates)
+shuffle_queue() {
+    local i j entry
+    for (( i = ${#FRAMEWORK_QUEUE[@]} - 1; i > 0; i-- )); do
+        next_random
+        # The low bits of an LCG cycle with a short period; use the high ones
+        j=$(( (RNG_STATE >> 16) % (i + 1) ))
+        entry=${FRAMEWORK_QUEUE[i]}
+        FRAMEWORK_QUEUE[i]=${FRAMEWORK_QUEUE[j]}
+        FRAMEWORK_QUEUE[j]=$entry
+    done
+}
+
+# Function to benchmark one queued framework. The first iteration runs the
+# framework with all its variants; later iterations rerun the server alone
+# as <framework>@run<n>.
+run_queued_framework() {
+    local entry=$1
+    local iteration=$2
+    local server_name start_command server_dir
+    IFS='|' read -r server_name start_command server_dir <<< "$entry"
+
+    if [ ${#RUN_SEQUENCE[@]} -gt 0 ] && [ "$COOLDOWN" -gt 0 ]; then
+        print_status "Cooling down for ${COOLDOWN}s..."
+        sleep "$COOLDOWN"
+    fi
+
+    if [ "$iteration" -eq 1 ]; then
+        print_status "Found framework: $server_name"
+        benchmark_framework "$server_name" "$start_command" "$server_dir"
+        RUN_SEQUENCE+=("$server_name")
+        return
+    fi
+
+    local run_name="$server_name@run$iteration"
+    print_status "Iteration $iteration of $ITERATIONS: $server_name"
+    SERVER_LIMITS=$(resource_limits_for "$server_name")
+    benchmark_server "$run_name" "$start_command" "$server_dir"
+    SERVER_LIMITS=""
+    record_framework_info "$run_name" "\"base\": \"$server_name\", \"variant\": \"iteration\", \"iteration\": $iteration"
+    RUN_SEQUENCE+=("$run_name")
+}
+
+# Auto-discover and benchmark servers from configuration, in the configured
+# run order
+discover_and_benchmark_servers() {
+    FRAMEWORK_QUEUE=()
+    discover_servers
+
+    RNG_STATE=$RUN_SEED
+    if [ "$SHUFFLE" = true ]; then
+        shuffle_queue
+    fi
+    if [ "$SHUFFLE" = true ] || [ "$ITERATIONS" -gt 1 ]; then
+        print_status "Run order (seed $RUN_SEED): $(printf '%s\n' "${FRAMEWORK_QUEUE[@]}" | cut -d'|' -f1 | paste -sd' ' -)"
+    fi
+
+    local entry iteration i offset count=${#FRAMEWORK_QUEUE[@]}
+    if [ "$INTERLEAVE" = true ]; then
+        # Every round starts one position later in the queue, so each
+        # framework takes a turn at running first. The last of a round is
+        # then never the first of the next, except with two frameworks,
+        # which keep plain A B A B.
+        for (( iteration = 1; iteration <= ITERATIONS; iteration++ )); do
+            offset=0
+            if [ "$count" -gt 2 ]; then
+                offset=$(( (iteration - 1) % count ))
+            fi
+            for (( i = 0; i < count; i++ )); do
+                run_queued_framework "${FRAMEWORK_QUEUE[(i + offset) % count]}" "$iteration"
+            done
+        done
+    else
+        for entry in "${FRAMEWORK_QUEUE[@]}"; do
+            for (( iteration = 1; iteration <= ITERATIONS; iteration++ )); do
+                run_queued_framework "$entry" "$iteration"
+            done
+        done
+    fi
+}
+
 # Check dependencies
 check_dependencies() {
     local missing_deps=()
 
     # Note: jq is optional for enhanced output formatting
 
-    if ! command -v wrk >/dev/null 2>&1; then
+    if [ "$LOAD_GENERATOR" = "wrk" ] && ! command -v wrk >/dev/null 2>&1; then
         missing_deps+=("wrk")
     fi
 
@@ -405,6 +1875,14 @@ check_dependencies() {
         missing_deps+=("curl")
     fi
 
+    if { [ "$PROFILE" = true ] || [ "$GC_TELEMETRY" = true ] || [ "$GC_MATRIX" = true ] || [ -n "$RESOURCE_PROFILE" ] || [ -n "$LOAD_PROFILE" ]; } && ! command -v jq >/dev/null 2>&1; then
+        missing_deps+=("jq")
+    fi
+
+    if { [ "$CPU_SWEEP" = true ] || [ "$ISOLATE_CPUS" = true ]; } && ! command -v taskset >/dev/null 2>&1; then
+        missing_deps+=("taskset")
+    fi
+
     if ! command -v lsof >/dev/null 2>&1; then
         missing_deps+=("lsof")
     fi
@@ -416,6 +1894,114 @@ check_dependencies() {
     fi
 }
 
+# Function to print a string escaped for use inside a JSON string
+json_escape() {
+    printf '%s' "$1" | sed 's/\\/\\\\/g; s/"/\\"/g'
+}
+
+# Function to read a setting of a benchmark.json section, such as
+# host_checks or warmup, with a default when jq or the setting is missing
+benchmark_setting() {
+    local section=$1
+    local key=$2
+    local default=$3
+
+    if command -v jq >/dev/null 2>&1 && [ -f ./benchmark.json ]; then
+        jq -r ".benchmark.$section.$key // \"$default\"" ./benchmark.json
+    else
+        echo "$default"
+    fi
+}
+
+# Function to fingerprint the host and check it for conditions that skew
+# results: a busy machine, a power-saving CPU governor, little free memory
+# and limits too low for the configured connections. Sets HOST_JSON and
+# HOST_WARNINGS.
+check_host() {
+    local os=$(uname -s)
+    local cpu_model="" cores="" governor="unknown" load="" somaxconn="" memory_mb=""
+
+    if [ -r /proc/cpuinfo ]; then
+        cpu_model=$(awk -F': ' '/^model name/ { print $2; exit }' /proc/cpuinfo)
+    fi
+    if [ -z "$cpu_model" ] && command -v lscpu >/dev/null 2>&1; then
+        cpu_model=$(lscpu | awk -F': *' '/^Model name/ { print $2; exit }')
+    fi
+    if [ -z "$cpu_model" ] && [ "$os" = "Darwin" ]; then
+        cpu_model=$(sysctl -n machdep.cpu.brand_string 2>/dev/null)
+    fi
+    cores=$(getconf _NPROCESSORS_ONLN 2>/dev/null || sysctl -n hw.ncpu 2>/dev/null || echo 1)
+
+    if [ -r /sys/devices/system/cpu/cpu0/cpufreq/scaling_governor ]; then
+        governor=$(cat /sys/devices/system/cpu/cpu0/cpufreq/scaling_governor)
+    fi
+
+    if [ -r /proc/loadavg ]; then
+        load=$(awk '{ print $1 }' /proc/loadavg)
+        somaxconn=$(cat /proc/sys/net/core/somaxconn 2>/dev/null || true)
+        memory_mb=$(awk '/^MemAvailable:/ { print int($2 / 1024) }' /proc/meminfo)
+    elif [ "$os" = "Darwin" ]; then
+        load=$(sysctl -n vm.loadavg 2>/dev/null | awk '{ print $2 }')
+        somaxconn=$(sysctl -n kern.ipc.somaxconn 2>/dev/null || true)
+        memory_mb=$(vm_stat | awk '/page size of/ { size = $8 } /Pages (free|inactive)/ { gsub(/\./, "", $NF); pages += $NF } END { print int(pages * size / 1048576) }')
+    fi
+
+    local open_files=$(ulimit -n 2>/dev/null || true)
+    # Numbers only in the JSON: "unlimited" and unknown limits are null
+    local open_files_json=null
+    if [[ "$open_files" =~ ^[0-9]+$ ]]; then
+        open_files_json=$open_files
+    fi
+    if ! [[ "$somaxconn" =~ ^[0-9]+$ ]]; then
+        somaxconn=""
+    fi
+    local go_version=$(go env GOVERSION 2>/dev/null || true)
+    local bun_version=$(bun --version 2>/dev/null || true)
+
+    local max_load=$(benchmark_setting host_checks max_load_per_cpu 0.5)
+    local min_memory=$(benchmark_setting host_checks min_available_memory_mb 1024)
+    local expected_governor=$(benchmark_setting host_checks cpu_governor performance)
+
+    HOST_WARNINGS=()
+    if [ -n "$load" ] && awk -v load="$load" -v cores="$cores" -v max="$max_load" 'BEGIN { exit !(load / cores > max) }'; then
+        HOST_WARNINGS+=("Load average $load on $cores CPU(s) is above $max_load per CPU; other processes compete with the benchmark")
+    fi
+    if [ "$governor" != "unknown" ] && [ "$governor" != "$expected_governor" ]; then
+        HOST_WARNINGS+=("CPU frequency governor is '$governor', not '$expected_governor'; clock speeds may vary between runs")
+    fi
+    if [ -n "$memory_mb" ] && [ "$memory_mb" -lt "$min_memory" ]; then
+        HOST_WARNINGS+=("Only ${memory_mb} MB of memory available (less than $min_memory MB)")
+    fi
+    if [ -n "$somaxconn" ] && [ "$somaxconn" -lt "$CONNECTIONS" ]; then
+        HOST_WARNINGS+=("net.core.somaxconn is $somaxconn, below $CONNECTIONS connections; connection bursts may be refused")
+    fi
+
+    local warnings_json=""
+    local warning
+    for warning in "${HOST_WARNINGS[@]}"; do
+        warnings_json="${warnings_json:+$warnings_json, }\"$(json_escape "$warning")\""
+    done
+
+    HOST_JSON="{\"os\": \"$os\", \"kernel\": \"$(json_escape "$(uname -r)")\", \"cpu_model\": \"$(json_escape "${cpu_model:-unknown}")\", \"cpu_cores\": $cores, \"cpu_governor\": \"$(json_escape "$governor")\", \"load_average\": ${load:-null}, \"somaxconn\": ${somaxconn:-null}, \"open_files_limit\": $open_files_json, \"memory_available_mb\": ${memory_mb:-null}, \"go_version\": \"$(json_escape "$go_version")\", \"bun_version\": \"$(json_escape "$bun_version")\", \"warnings\": [$warnings_json]}"
+
+    print_status "Host: ${cpu_model:-unknown CPU} ($cores CPUs), $os $(uname -r), ${go_version:-no Go}, Bun ${bun_version:-missing}"
+
+    # Each connection needs a descriptor in the load generator and in the
+    # server, so a low limit makes the run fail rather than just be noisy
+    if [ "$open_files_json" != null ] && [ "$open_files" -lt $((CONNECTIONS + 100)) ]; then
+        print_error "Open file limit (ulimit -n) is $open_files, too low for $CONNECTIONS connections; raise it with ulimit -n"
+        exit 1
+    fi
+
+    for warning in "${HOST_WARNINGS[@]}"; do
+        print_warning "Noisy host: $warning"
+    done
+    if [ ${#HOST_WARNINGS[@]} -gt 0 ] && [ "$STRICT_HOST" = true ]; then
+        print_error "Pre-flight checks failed (--strict-host)"
+        exit 1
+    fi
+}
+
 # Check if running in CI environment
 if [ "${CI:-}" = "true" ] || [ "${GITHUB_ACTIONS:-}" = "true" ] || [ "${CONTINUOUS_INTEGRATION:-}" = "true" ]; then
     print_status "Running in CI environment"
@@ -430,6 +2016,7 @@ if [ "${CI:-}" = "true" ] || [ "${GITHUB_ACTIONS:-}" = "true" ] || [ "${CONTINUO
         THREADS=2
     fi
     WARMUP_TIME=2
+    WARMUP_MAX=20
 fi
 
 # Parse command line arguments
@@ -450,12 +2037,148 @@ while [[ $# -gt 0 ]]; do
             THREADS_SET=true
             shift 2
             ;;
+        -p|--pipeline)
+            PIPELINE_DEPTH="$2"
+            shift 2
+            ;;
+        -g|--load-generator)
+            LOAD_GENERATOR="$2"
+            shift 2
+            ;;
+        -n|--network-profile)
+            NETWORK_PROFILE="$2"
+            shift 2
+            ;;
+        -l|--load-profile)
+            LOAD_PROFILE="$2"
+            shift 2
+            ;;
+        --trace-rate)
+            TRACE_RATE="$2"
+            shift 2
+            ;;
+        --heatmap)
+            SHOW_HEATMAP=true
+            shift
+            ;;
+        --profile)
+            PROFILE=true
+            shift
+            ;;
+        --pgo)
+            PGO=true
+            shift
+            ;;
+        --cpu-sweep)
+            CPU_SWEEP=true
+            shift
+            ;;
+        --cold-starts)
+            COLD_STARTS="$2"
+            shift 2
+            ;;
+        --footprint)
+            FOOTPRINT=true
+            shift
+            ;;
+        --shutdown-test)
+            SHUTDOWN_TEST=true
+            shift
+            ;;
+        --gc-telemetry)
+            GC_TELEMETRY=true
+            shift
+            ;;
+        --gc-matrix)
+            GC_MATRIX=true
+            shift
+            ;;
+        --strict-host)
+            STRICT_HOST=true
+            shift
+            ;;
+        --warmup-max)
+            WARMUP_MAX="$2"
+            shift 2
+            ;;
+        --no-calibration)
+            CALIBRATE=false
+            shift
+            ;;
+        --bound-margin)
+            LOADGEN_BOUND_MARGIN="$2"
+            shift 2
+            ;;
+        --shuffle)
+            SHUFFLE=true
+            shift
+            ;;
+        --seed)
+            RUN_SEED="$2"
+            SHUFFLE=true
+            shift 2
+            ;;
+        --iterations)
+            ITERATIONS="$2"
+            shift 2
+            ;;
+        --interleave)
+            INTERLEAVE=true
+            shift
+            ;;
+        --cooldown)
+            COOLDOWN="$2"
+            shift 2
+            ;;
+        --isolate-cpus)
+            ISOLATE_CPUS=true
+            shift
+            ;;
+        --resource-profile)
+            RESOURCE_PROFILE="$2"
+            shift 2
+            ;;
+        --slow-clients)
+            SLOW_CLIENTS="$2"
+            shift 2
+            ;;
+        --slow-interval)
+            SLOW_INTERVAL="$2"
+            shift 2
+            ;;
         -h|--help)
             echo "Usage: $0 [OPTIONS]"
             echo "Options:"
             echo "  -d, --duration SECONDS    Benchmark duration (default: $BENCHMARK_DURATION)"
             echo "  -c, --connections NUM     Number of connections (default: $CONNECTIONS)"
             echo "  -t, --threads NUM         Number of threads (default: $THREADS)"
+            echo "  -p, --pipeline DEPTH      HTTP/1.1 requests in flight per connection (default: $PIPELINE_DEPTH)"
+            echo "  -g, --load-generator GEN  Load generator: wrk or go (default: $LOAD_GENERATOR)"
+            echo "  -n, --network-profile NAME  Emulated network from benchmark.json (default: $NETWORK_PROFILE)"
+            echo "  -l, --load-profile NAME   Load test profile from benchmark.json; endurance also detects memory leaks"
+            echo "      --trace-rate NUM      Traced requests/sec for the timing breakdown (default: off)"
+            echo "      --profile             Capture pprof CPU, heap, allocs, mutex and block profiles of Go servers"
+            echo "      --pgo                 Also benchmark Go servers rebuilt with profile-guided optimization (NAME+pgo)"
+            echo "      --cpu-sweep           Also benchmark every server pinned to 1, 2, 4 and all CPUs (NAME@<n>cpu)"
+            echo "      --cold-starts NUM     Also measure NUM cold starts per server: startup time and warm-up curve (default: off)"
+            echo "      --footprint           Also record build time, binary size and dependency counts of every server"
+            echo "      --gc-matrix           Also benchmark Go servers under each GOGC/GOMEMLIMIT of benchmark.json (NAME@gogc<n>[-mem<limit>])"
+            echo "      --gc-telemetry        Record GC cycles, pauses, heap goal and goroutines of Go servers per endpoint"
+            echo "      --isolate-cpus        Pin the load generator and the servers to disjoint CPU sets"
+            echo "      --resource-profile NAME  Run servers in a cgroup v2 with CPU/memory limits from benchmark.json"
+            echo "      --heatmap             Print the load generator's report and latency heatmap after each run"
+            echo "      --shutdown-test       Also send every server SIGTERM under load and measure how it drains"
+            echo "      --strict-host         Abort instead of warning when the pre-flight checks find a noisy host"
+            echo "      --warmup-max SECONDS  Cap on the warm-up, which runs until throughput and P99 are stable (-g go; default: benchmark.json)"
+            echo "      --no-calibration      Skip measuring the load generator's ceiling against a canned-response server"
+            echo "      --bound-margin PCT    Mark results within PCT% of the load generator's ceiling as load-generator bound (default: 10)"
+            echo "      --shuffle             Benchmark the frameworks in a random order (recorded with its seed)"
+            echo "      --seed NUM            Seed of the shuffled order, to reproduce a run (implies --shuffle)"
+            echo "      --iterations NUM      Benchmark every framework NUM times, recorded as NAME@run<n> (default: $ITERATIONS)"
+            echo "      --interleave          Run the iterations round by round (A B A B) instead of back to back"
+            echo "      --cooldown SECONDS    Idle time between servers (default: $COOLDOWN)"
+            echo "      --slow-clients NUM    Also run a slow-client resilience test with NUM slow connections (default: off)"
+            echo "      --slow-interval DUR   Delay between bytes trickled by slow clients (default: $SLOW_INTERVAL)"
             echo "  -h, --help               Show this help message"
             exit 0
             ;;
@@ -466,8 +2189,118 @@ while [[ $# -gt 0 ]]; do
     esac
 done
 
+case "$LOAD_GENERATOR" in
+    wrk|go) ;;
+    *)
+        print_error "Unknown load generator: $LOAD_GENERATOR (expected wrk or go)"
+        exit 1
+        ;;
+esac
+
+if ! [[ "$PIPELINE_DEPTH" =~ ^[1-9][0-9]*$ ]]; then
+    print_error "Pipeline depth must be a positive integer, got: $PIPELINE_DEPTH"
+    exit 1
+fi
+
+if ! [[ "$TRACE_RATE" =~ ^[0-9]+$ ]]; then
+    print_error "Trace rate must be a non-negative integer, got: $TRACE_RATE"
+    exit 1
+fi
+
+if ! [[ "$COLD_STARTS" =~ ^[0-9]+$ ]]; then
+    print_error "Cold starts must be a non-negative integer, got: $COLD_STARTS"
+    exit 1
+fi
+
+if [ -z "$LOADGEN_BOUND_MARGIN" ]; then
+    LOADGEN_BOUND_MARGIN=$(benchmark_setting loadgen_calibration bound_margin_percent 10)
+fi
+if ! [[ "$LOADGEN_BOUND_MARGIN" =~ ^[0-9]+(\.[0-9]+)?$ ]] || awk -v m="$LOADGEN_BOUND_MARGIN" 'BEGIN { exit !(m >= 100) }'; then
+    print_error "Bound margin must be a percentage below 100, got: $LOADGEN_BOUND_MARGIN"
+    exit 1
+fi
+LOADGEN_CPU_SATURATION=$(benchmark_setting loadgen_calibration cpu_saturation_percent 90)
+
+if [ -z "$RUN_SEED" ]; then
+    RUN_SEED=$(( RANDOM * 32768 + RANDOM ))
+fi
+if ! [[ "$RUN_SEED" =~ ^[0-9]+$ ]] || [ "$RUN_SEED" -ge 2147483648 ]; then
+    print_error "Seed must be an integer between 0 and 2147483647, got: $RUN_SEED"
+    exit 1
+fi
+
+if ! [[ "$ITERATIONS" =~ ^[1-9][0-9]*$ ]]; then
+    print_error "Iterations must be a positive integer, got: $ITERATIONS"
+    exit 1
+fi
+
+if ! [[ "$COOLDOWN" =~ ^[0-9]+$ ]]; then
+    print_error "Cool-down must be a non-negative number of seconds, got: $COOLDOWN"
+    exit 1
+fi
+
+if ! [[ "$SLOW_CLIENTS" =~ ^[0-9]+$ ]]; then
+    print_error "Slow clients must be a non-negative integer, got: $SLOW_CLIENTS"
+    exit 1
+fi
+
+# wrk has no response framing for pipelined requests, so pipelining always
+# runs on the Go load generator
+if [ "$PIPELINE_DEPTH" -gt 1 ] && [ "$LOAD_GENERATOR" != "go" ]; then
+    print_warning "Pipelining requires the Go load generator, switching to --load-generator go"
+    LOAD_GENERATOR="go"
+fi
+
+if [ "$TRACE_RATE" -gt 0 ] && [ "$LOAD_GENERATOR" != "go" ]; then
+    print_warning "The timing breakdown requires the Go load generator, switching to --load-generator go"
+    LOAD_GENERATOR="go"
+fi
+
+if [ "$SHOW_HEATMAP" = true ] && [ "$LOAD_GENERATOR" != "go" ]; then
+    print_warning "Latency heatmaps require the Go load generator, switching to --load-generator go"
+    LOAD_GENERATOR="go"
+fi
+
 # Run dependency check
 check_dependencies
 
+# Load test profiles set duration, connections and threads unless they were
+# given on the command line
+if [ -n "$LOAD_PROFILE" ]; then
+    load_profile=$(jq -c ".benchmark.load_test_profiles[\"$LOAD_PROFILE\"] // empty" ./benchmark.json)
+    if [ -z "$load_profile" ]; then
+        print_error "Unknown load profile: $LOAD_PROFILE"
+        print_status "Available profiles: $(jq -r '.benchmark.load_test_profiles | keys | join(", ")' ./benchmark.json)"
+        exit 1
+    fi
+    if [ -z "${BENCHMARK_DURATION_SET:-}" ]; then
+        BENCHMARK_DURATION=$(echo "$load_profile" | jq -r ".duration // $BENCHMARK_DURATION")
+    fi
+    if [ -z "${CONNECTIONS_SET:-}" ]; then
+        CONNECTIONS=$(echo "$load_profile" | jq -r ".connections // $CONNECTIONS")
+    fi
+    if [ -z "${THREADS_SET:-}" ]; then
+        THREADS=$(echo "$load_profile" | jq -r ".threads // $THREADS")
+    fi
+    if [ "$(echo "$load_profile" | jq -r '.memory_sampling // false')" = true ]; then
+        MEMORY_SAMPLING=true
+    fi
+    print_status "Load profile '$LOAD_PROFILE': $(echo "$load_profile" | jq -r '.description // ""')"
+fi
+
+if [ -z "$WARMUP_MAX" ]; then
+    WARMUP_MAX=$(benchmark_setting warmup max_seconds 60)
+fi
+if ! [[ "$WARMUP_MAX" =~ ^[1-9][0-9]*$ ]] || [ "$WARMUP_MAX" -lt "$WARMUP_TIME" ]; then
+    print_error "Warm-up cap must be a positive number of seconds of at least $WARMUP_TIME, got: $WARMUP_MAX"
+    exit 1
+fi
+
+if [ -n "$RESOURCE_PROFILE" ] && [ -z "$(jq -r ".benchmark.resource_profiles[\"$RESOURCE_PROFILE\"] // empty" ./benchmark.json)" ]; then
+    print_error "Unknown resource profile: $RESOURCE_PROFILE"
+    print_status "Available profiles: $(jq -r '.benchmark.resource_profiles | keys | join(", ")' ./benchmark.json)"
+    exit 1
+fi
+
 # Run main function
 main
@@ -0,0 +1,91 @@
+// Command cannedserver is the calibration target of benchmark.sh: a TCP
+// server that answers every request with the same precomputed HTTP response,
+// without parsing anything but the end of the request headers. It costs so
+// little per request that loading it measures the ceiling of the load
+// generator itself. Requests must not have a body; pipelined requests are
+// answered in order.
+package main
+
+import (
+	"flag"
+	"log"
+	"net"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+)
+
+const body = `{"message":"Hello, World!","timestamp":"2006-01-02T15:04:05.999999999Z"}` + "\n"
+
+var response = []byte("HTTP/1.1 200 OK\r\n" +
+	"Content-Type: application/json\r\n" +
+	"Date: Mon, 02 Jan 2006 15:04:05 GMT\r\n" +
+	"Content-Length: " + strconv.Itoa(len(body)) + "\r\n" +
+	"\r\n" + body)
+
+// headerEnd is the blank line that ends the headers of a request.
+var headerEnd = []byte("\r\n\r\n")
+
+func main() {
+	addr := flag.String("addr", ":18090", "address to accept connections on")
+	flag.Parse()
+
+	listener, err := net.Listen("tcp", *addr)
+	if err != nil {
+		log.Fatalf("cannedserver: %v", err)
+	}
+
+	go func() {
+		stop := make(chan os.Signal, 1)
+		signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
+		<-stop
+		listener.Close()
+	}()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		go serve(conn)
+	}
+}
+
+// serve writes one response for every request read from conn, batching the
+// responses to the requests of one read into one write.
+func serve(conn net.Conn) {
+	defer conn.Close()
+
+	buf := make([]byte, 16*1024)
+	var out []byte
+	matched := 0
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			return
+		}
+
+		out = out[:0]
+		for _, b := range buf[:n] {
+			switch {
+			case b == headerEnd[matched]:
+				matched++
+			case b == headerEnd[0]:
+				matched = 1
+			default:
+				matched = 0
+			}
+			if matched == len(headerEnd) {
+				out = append(out, response...)
+				matched = 0
+			}
+		}
+
+		if len(out) > 0 {
+			if _, err := conn.Write(out); err != nil {
+				return
+			}
+		}
+	}
+}
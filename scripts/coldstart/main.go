@@ -0,0 +1,286 @@
+// Command coldstart measures how long a server takes to come up. It spawns
+// the server command, polls a readiness URL every millisecond until the first
+// successful response, then sends the first requests to the fresh process
+// one at a time to record the warm-up curve. benchmark.sh runs it for
+// --cold-starts.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"sort"
+	"syscall"
+	"time"
+)
+
+type Config struct {
+	ReadyURL   string
+	RequestURL string
+	Dir        string
+	Runs       int
+	Requests   int
+	Poll       time.Duration
+	Timeout    time.Duration
+	Output     string
+	Command    []string
+}
+
+// Result is recorded as the "cold_start" field of a framework in the
+// results' framework_info (see ColdStart in scripts/generate_readme.go).
+type Result struct {
+	Runs                 int       `json:"runs"`
+	StartupMs            []float64 `json:"startup_ms"`
+	StartupMedianMs      float64   `json:"startup_median_ms"`
+	StartupMaxMs         float64   `json:"startup_max_ms"`
+	FirstRequestUs       []int64   `json:"first_request_us"`
+	FirstRequestMedianUs int64     `json:"first_request_median_us"`
+	FirstRequestMaxUs    int64     `json:"first_request_max_us"`
+	WarmupCurveUs        []int64   `json:"warmup_curve_us"`
+}
+
+// run is one cold start: spawn to first successful response, and the
+// latencies of the requests that followed.
+type run struct {
+	startup   time.Duration
+	latencies []int64
+}
+
+func main() {
+	var cfg Config
+	flag.StringVar(&cfg.ReadyURL, "url", "http://localhost:8080/health", "URL polled until the server responds successfully")
+	flag.StringVar(&cfg.RequestURL, "request-url", "http://localhost:8080/", "URL of the warm-up requests sent once the server is up")
+	flag.StringVar(&cfg.Dir, "dir", ".", "directory the server command runs in")
+	flag.IntVar(&cfg.Runs, "runs", 5, "number of cold starts")
+	flag.IntVar(&cfg.Requests, "requests", 100, "sequential requests recorded after each start (the warm-up curve)")
+	flag.DurationVar(&cfg.Poll, "poll", time.Millisecond, "interval between readiness polls")
+	flag.DurationVar(&cfg.Timeout, "timeout", 30*time.Second, "time allowed for the server to come up")
+	flag.StringVar(&cfg.Output, "o", "", "write the JSON result to this file instead of stdout")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: coldstart [flags] -- command [args...]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	cfg.Command = flag.Args()
+
+	if err := cfg.validate(); err != nil {
+		log.Fatalf("coldstart: %v", err)
+	}
+
+	runs := make([]run, 0, cfg.Runs)
+	for i := 0; i < cfg.Runs; i++ {
+		r, err := coldStart(&cfg)
+		if err != nil {
+			log.Fatalf("coldstart: run %d: %v", i+1, err)
+		}
+		fmt.Fprintf(os.Stderr, "coldstart: run %d: up after %.1fms, first request %dus\n",
+			i+1, float64(r.startup.Microseconds())/1000, r.latencies[0])
+		runs = append(runs, r)
+	}
+
+	data, err := json.Marshal(newResult(runs))
+	if err != nil {
+		log.Fatalf("coldstart: %v", err)
+	}
+	data = append(data, '\n')
+
+	if cfg.Output == "" {
+		os.Stdout.Write(data)
+		return
+	}
+	if err := os.WriteFile(cfg.Output, data, 0644); err != nil {
+		log.Fatalf("coldstart: %v", err)
+	}
+}
+
+func (cfg *Config) validate() error {
+	if len(cfg.Command) == 0 {
+		return fmt.Errorf("no server command given")
+	}
+	for _, raw := range []string{cfg.ReadyURL, cfg.RequestURL} {
+		u, err := url.Parse(raw)
+		if err != nil {
+			return fmt.Errorf("invalid url: %v", err)
+		}
+		if u.Scheme != "http" {
+			return fmt.Errorf("only http:// URLs are supported, got %q", raw)
+		}
+	}
+	if cfg.Runs < 1 {
+		return fmt.Errorf("runs must be at least 1")
+	}
+	if cfg.Requests < 1 {
+		return fmt.Errorf("requests must be at least 1")
+	}
+	if cfg.Poll <= 0 || cfg.Timeout <= 0 {
+		return fmt.Errorf("poll interval and timeout must be positive")
+	}
+	return nil
+}
+
+func coldStart(cfg *Config) (run, error) {
+	ready, _ := url.Parse(cfg.ReadyURL)
+	// A server still shutting down from the previous run would answer the
+	// readiness poll before the new process is up.
+	if err := waitForPortFree(ready.Host, cfg.Timeout); err != nil {
+		return run{}, err
+	}
+
+	cmd := exec.Command(cfg.Command[0], cfg.Command[1:]...)
+	cmd.Dir = cfg.Dir
+	cmd.Stderr = os.Stderr
+	// Own process group, so wrappers such as `bun run` or taskset are
+	// stopped together with the server they start.
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+
+	start := time.Now()
+	if err := cmd.Start(); err != nil {
+		return run{}, err
+	}
+	exited := make(chan error, 1)
+	go func() { exited <- cmd.Wait() }()
+	defer stop(cmd, exited)
+
+	startup, err := pollReady(cfg, start, exited)
+	if err != nil {
+		return run{}, err
+	}
+
+	// One keep-alive connection, like a single client hitting a fresh
+	// instance; the first request also pays for the connection.
+	client := &http.Client{Timeout: cfg.Timeout, Transport: &http.Transport{MaxIdleConnsPerHost: 1}}
+	defer client.CloseIdleConnections()
+
+	latencies := make([]int64, cfg.Requests)
+	for i := range latencies {
+		sent := time.Now()
+		if err := get(client, cfg.RequestURL); err != nil {
+			return run{}, fmt.Errorf("request %d: %v", i+1, err)
+		}
+		latencies[i] = time.Since(sent).Microseconds()
+	}
+
+	return run{startup: startup, latencies: latencies}, nil
+}
+
+// pollReady polls the readiness URL on fresh connections until it answers
+// successfully and returns the time since the process was spawned.
+func pollReady(cfg *Config, start time.Time, exited <-chan error) (time.Duration, error) {
+	client := &http.Client{
+		Timeout:   100 * time.Millisecond,
+		Transport: &http.Transport{DisableKeepAlives: true},
+	}
+	deadline := start.Add(cfg.Timeout)
+
+	for time.Now().Before(deadline) {
+		if err := get(client, cfg.ReadyURL); err == nil {
+			return time.Since(start), nil
+		}
+		select {
+		case err := <-exited:
+			return 0, fmt.Errorf("server exited before responding: %v", err)
+		case <-time.After(cfg.Poll):
+		}
+	}
+	return 0, fmt.Errorf("server did not respond within %s", cfg.Timeout)
+}
+
+func get(client *http.Client, target string) error {
+	resp, err := client.Get(target)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func waitForPortFree(host string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		conn, err := net.DialTimeout("tcp", host, 100*time.Millisecond)
+		if err != nil {
+			return nil
+		}
+		conn.Close()
+		time.Sleep(50 * time.Millisecond)
+	}
+	return fmt.Errorf("%s is still in use by another process", host)
+}
+
+// stop sends SIGTERM to the server's process group and SIGKILL if it has
+// not exited after five seconds.
+func stop(cmd *exec.Cmd, exited <-chan error) {
+	pgid := -cmd.Process.Pid
+	if err := syscall.Kill(pgid, syscall.SIGTERM); err != nil && !errors.Is(err, syscall.ESRCH) {
+		cmd.Process.Kill()
+	}
+	select {
+	case <-exited:
+	case <-time.After(5 * time.Second):
+		syscall.Kill(pgid, syscall.SIGKILL)
+		<-exited
+	}
+	// Children that outlived the group leader
+	syscall.Kill(pgid, syscall.SIGKILL)
+}
+
+func newResult(runs []run) *Result {
+	result := &Result{Runs: len(runs)}
+
+	startups := make([]float64, len(runs))
+	firsts := make([]int64, len(runs))
+	for i, r := range runs {
+		startups[i] = float64(r.startup.Microseconds()) / 1000
+		firsts[i] = r.latencies[0]
+	}
+	result.StartupMs = startups
+	result.FirstRequestUs = firsts
+
+	sortedStartups := append([]float64(nil), startups...)
+	sort.Float64s(sortedStartups)
+	result.StartupMedianMs = sortedStartups[len(sortedStartups)/2]
+	result.StartupMaxMs = sortedStartups[len(sortedStartups)-1]
+
+	result.FirstRequestMedianUs = median(firsts)
+	for _, v := range firsts {
+		if v > result.FirstRequestMaxUs {
+			result.FirstRequestMaxUs = v
+		}
+	}
+
+	// The curve is the median latency of the i-th request across runs, so a
+	// single noisy start does not shape it.
+	result.WarmupCurveUs = make([]int64, len(runs[0].latencies))
+	column := make([]int64, len(runs))
+	for i := range result.WarmupCurveUs {
+		for j, r := range runs {
+			column[j] = r.latencies[i]
+		}
+		result.WarmupCurveUs[i] = median(column)
+	}
+
+	return result
+}
+
+// median returns the middle value (the upper one for an even count), or 0
+// for no values.
+func median(values []int64) int64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]int64(nil), values...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return sorted[len(sorted)/2]
+}
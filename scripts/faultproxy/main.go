@@ -0,0 +1,234 @@
+// Command faultproxy is a TCP proxy that benchmark.sh places between the
+// load generator and the server under test to emulate a real network:
+// added latency and jitter, a bandwidth cap, packet-sized write splitting
+// and random connection resets.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"math/rand"
+	"net"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+type Profile struct {
+	Latency          time.Duration
+	Jitter           time.Duration
+	BandwidthKbps    int
+	SegmentBytes     int
+	ResetProbability float64
+}
+
+type counters struct {
+	connections atomic.Int64
+	resets      atomic.Int64
+	bytesUp     atomic.Int64
+	bytesDown   atomic.Int64
+}
+
+// chunk is one read from a connection, held until its delivery time.
+type chunk struct {
+	data    []byte
+	deliver time.Time
+}
+
+// limiter shapes one direction of the link. It is shared by all connections
+// so the bandwidth cap applies to the link rather than to each connection.
+type limiter struct {
+	mu          sync.Mutex
+	bytesPerSec float64
+	next        time.Time
+}
+
+// reserve books n bytes on the link and returns when they finish sending.
+func (l *limiter) reserve(n int) time.Time {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	l.next = l.next.Add(time.Duration(float64(n) / l.bytesPerSec * float64(time.Second)))
+	return l.next
+}
+
+func main() {
+	var profile Profile
+	listen := flag.String("listen", ":18080", "address to accept connections on")
+	target := flag.String("target", "localhost:8080", "address of the server under test")
+	flag.DurationVar(&profile.Latency, "latency", 0, "one-way delay added in each direction")
+	flag.DurationVar(&profile.Jitter, "jitter", 0, "random variation (+/-) applied to the delay")
+	flag.IntVar(&profile.BandwidthKbps, "bandwidth-kbps", 0, "link bandwidth cap per direction in kbit/s (0 = unlimited)")
+	flag.IntVar(&profile.SegmentBytes, "segment-bytes", 0, "split writes into segments of at most this many bytes (0 = no splitting)")
+	flag.Float64Var(&profile.ResetProbability, "reset-probability", 0, "probability that a forwarded segment triggers a connection reset")
+	flag.Parse()
+
+	if profile.Jitter > profile.Latency {
+		log.Fatalf("faultproxy: jitter (%s) must not exceed latency (%s)", profile.Jitter, profile.Latency)
+	}
+	if profile.ResetProbability < 0 || profile.ResetProbability > 1 {
+		log.Fatalf("faultproxy: reset probability must be between 0 and 1")
+	}
+
+	ln, err := net.Listen("tcp", *listen)
+	if err != nil {
+		log.Fatalf("faultproxy: %v", err)
+	}
+
+	var stats counters
+	var up, down *limiter
+	if profile.BandwidthKbps > 0 {
+		bytesPerSec := float64(profile.BandwidthKbps) * 1000 / 8
+		up = &limiter{bytesPerSec: bytesPerSec}
+		down = &limiter{bytesPerSec: bytesPerSec}
+	}
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sigs
+		ln.Close()
+		fmt.Fprintf(os.Stderr, "faultproxy: %d connections, %d resets injected, %d bytes up, %d bytes down\n",
+			stats.connections.Load(), stats.resets.Load(), stats.bytesUp.Load(), stats.bytesDown.Load())
+		os.Exit(0)
+	}()
+
+	log.Printf("faultproxy: %s -> %s (latency %s ±%s, bandwidth %d kbit/s, segment %d bytes, reset probability %g)",
+		*listen, *target, profile.Latency, profile.Jitter, profile.BandwidthKbps, profile.SegmentBytes, profile.ResetProbability)
+
+	for {
+		client, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		stats.connections.Add(1)
+		go proxy(client, *target, &profile, up, down, &stats)
+	}
+}
+
+func proxy(client net.Conn, target string, profile *Profile, up, down *limiter, stats *counters) {
+	server, err := net.Dial("tcp", target)
+	if err != nil {
+		client.Close()
+		return
+	}
+
+	var once sync.Once
+	reset := func(injected bool) {
+		once.Do(func() {
+			if injected {
+				stats.resets.Add(1)
+				// A zero linger turns Close into a RST on both sides.
+				if tcp, ok := client.(*net.TCPConn); ok {
+					tcp.SetLinger(0)
+				}
+				if tcp, ok := server.(*net.TCPConn); ok {
+					tcp.SetLinger(0)
+				}
+			}
+			client.Close()
+			server.Close()
+		})
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		pipe(client, server, profile, up, &stats.bytesUp, reset)
+	}()
+	pipe(server, client, profile, down, &stats.bytesDown, reset)
+	wg.Wait()
+	reset(false)
+}
+
+// pipe forwards src to dst. Reads are queued with their delivery time so that
+// latency is added without limiting throughput to one chunk per delay.
+func pipe(src, dst net.Conn, profile *Profile, link *limiter, transferred *atomic.Int64, reset func(bool)) {
+	queue := make(chan chunk, 1024)
+
+	go func() {
+		defer close(queue)
+		var last time.Time
+		for {
+			buf := make([]byte, 32*1024)
+			n, err := src.Read(buf)
+			if n > 0 {
+				deliver := time.Now().Add(delay(profile))
+				// Jitter must not reorder bytes within a stream.
+				if deliver.Before(last) {
+					deliver = last
+				}
+				last = deliver
+				queue <- chunk{data: buf[:n], deliver: deliver}
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	for c := range queue {
+		time.Sleep(time.Until(c.deliver))
+
+		for _, segment := range split(c.data, profile.SegmentBytes) {
+			if profile.ResetProbability > 0 && rand.Float64() < profile.ResetProbability {
+				reset(true)
+				drain(queue)
+				return
+			}
+			if link != nil {
+				time.Sleep(time.Until(link.reserve(len(segment))))
+			}
+			if _, err := dst.Write(segment); err != nil {
+				reset(false)
+				drain(queue)
+				return
+			}
+			transferred.Add(int64(len(segment)))
+		}
+	}
+
+	// The source closed: pass the half-close on so pipelined responses
+	// already in flight still reach the client.
+	if tcp, ok := dst.(*net.TCPConn); ok {
+		tcp.CloseWrite()
+	} else {
+		reset(false)
+	}
+}
+
+func delay(profile *Profile) time.Duration {
+	d := profile.Latency
+	if profile.Jitter > 0 {
+		d += time.Duration(rand.Int63n(int64(2*profile.Jitter))) - profile.Jitter
+	}
+	return d
+}
+
+func split(data []byte, size int) [][]byte {
+	if size <= 0 || len(data) <= size {
+		return [][]byte{data}
+	}
+	segments := make([][]byte, 0, (len(data)+size-1)/size)
+	for len(data) > size {
+		segments = append(segments, data[:size])
+		data = data[size:]
+	}
+	return append(segments, data)
+}
+
+func drain(queue <-chan chunk) {
+	go func() {
+		for range queue {
+		}
+	}()
+}
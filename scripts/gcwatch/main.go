@@ -0,0 +1,267 @@
+// Command gcwatch samples the runtime/metrics of a Go server (served by the
+// server's metrics.go) once per interval until it is stopped with SIGINT or
+// SIGTERM, then writes GC and scheduler telemetry for the sampled window:
+// GC cycles, pause time and percentiles, heap goal, goroutines and
+// scheduling latency, in total and per interval. benchmark.sh runs it
+// alongside every endpoint run for --gc-telemetry.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+const (
+	gcCyclesMetric     = "/gc/cycles/total:gc-cycles"
+	heapGoalMetric     = "/gc/heap/goal:bytes"
+	goroutinesMetric   = "/sched/goroutines:goroutines"
+	schedLatencyMetric = "/sched/latencies:seconds"
+)
+
+// pauseMetrics are the stop-the-world GC pause histograms, newest name
+// first: Go 1.22 replaced /gc/pauses:seconds.
+var pauseMetrics = []string{"/sched/pauses/total/gc:seconds", "/gc/pauses:seconds"}
+
+type histogram struct {
+	Counts  []uint64  `json:"counts"`
+	Buckets []float64 `json:"buckets"`
+}
+
+// snapshot is one read of the server's /debug/metrics.
+type snapshot struct {
+	gcCycles     uint64
+	heapGoal     uint64
+	goroutines   uint64
+	pauses       *histogram
+	schedLatency *histogram
+}
+
+// Result is stored as the "gc" field of an endpoint result (see
+// GCTelemetry in scripts/generate_readme.go).
+type Result struct {
+	Samples          int        `json:"samples"`
+	GCCycles         uint64     `json:"gc_cycles"`
+	PauseTotalMs     float64    `json:"pause_total_ms"`
+	PauseP50Us       float64    `json:"pause_p50_us"`
+	PauseP99Us       float64    `json:"pause_p99_us"`
+	PauseMaxUs       float64    `json:"pause_max_us"`
+	HeapGoalMinBytes uint64     `json:"heap_goal_min_bytes"`
+	HeapGoalMaxBytes uint64     `json:"heap_goal_max_bytes"`
+	GoroutinesMin    uint64     `json:"goroutines_min"`
+	GoroutinesMax    uint64     `json:"goroutines_max"`
+	SchedLatencyP50  float64    `json:"sched_latency_p50_us"`
+	SchedLatencyP99  float64    `json:"sched_latency_p99_us"`
+	Seconds          []GCSecond `json:"seconds"`
+}
+
+// GCSecond is the GC activity of one sampling interval. Second counts from
+// 1 like the load generator's timeseries, so with the default 1s interval
+// the two line up; the last entry may cover a partial interval.
+type GCSecond struct {
+	Second        int     `json:"second"`
+	GCCycles      uint64  `json:"gc_cycles"`
+	PauseUs       float64 `json:"pause_us"`
+	HeapGoalBytes uint64  `json:"heap_goal_bytes"`
+	Goroutines    uint64  `json:"goroutines"`
+}
+
+func main() {
+	url := flag.String("url", "http://localhost:6061/debug/metrics", "runtime/metrics endpoint of the server (metrics.go)")
+	interval := flag.Duration("interval", time.Second, "sampling interval")
+	output := flag.String("o", "", "write the JSON result to this file instead of stdout")
+	flag.Parse()
+
+	client := &http.Client{Timeout: time.Second}
+	first, err := read(client, *url)
+	if err != nil {
+		log.Fatalf("gcwatch: %v", err)
+	}
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
+	ticker := time.NewTicker(*interval)
+	defer ticker.Stop()
+
+	snapshots := []*snapshot{first}
+	for running := true; running; {
+		select {
+		case <-ticker.C:
+		case <-stop:
+			running = false
+		}
+		// A server that went away mid-run ends the window
+		s, err := read(client, *url)
+		if err != nil {
+			break
+		}
+		snapshots = append(snapshots, s)
+	}
+
+	data, err := json.Marshal(summarize(snapshots))
+	if err != nil {
+		log.Fatalf("gcwatch: %v", err)
+	}
+	data = append(data, '\n')
+
+	if *output == "" {
+		os.Stdout.Write(data)
+		return
+	}
+	if err := os.WriteFile(*output, data, 0644); err != nil {
+		log.Fatalf("gcwatch: %v", err)
+	}
+}
+
+func read(client *http.Client, url string) (*snapshot, error) {
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: status %d", url, resp.StatusCode)
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+		return nil, err
+	}
+
+	s := &snapshot{}
+	json.Unmarshal(raw[gcCyclesMetric], &s.gcCycles)
+	json.Unmarshal(raw[heapGoalMetric], &s.heapGoal)
+	json.Unmarshal(raw[goroutinesMetric], &s.goroutines)
+	for _, name := range pauseMetrics {
+		if h := decodeHistogram(raw[name]); h != nil {
+			s.pauses = h
+			break
+		}
+	}
+	s.schedLatency = decodeHistogram(raw[schedLatencyMetric])
+	return s, nil
+}
+
+func decodeHistogram(raw json.RawMessage) *histogram {
+	if raw == nil {
+		return nil
+	}
+	var h histogram
+	if err := json.Unmarshal(raw, &h); err != nil || len(h.Buckets) != len(h.Counts)+1 {
+		return nil
+	}
+	return &h
+}
+
+// delta returns the counts recorded between two reads of a cumulative
+// histogram.
+func delta(before, after *histogram) *histogram {
+	if before == nil || after == nil || len(before.Counts) != len(after.Counts) {
+		return nil
+	}
+	d := &histogram{Counts: make([]uint64, len(after.Counts)), Buckets: after.Buckets}
+	for i := range after.Counts {
+		d.Counts[i] = after.Counts[i] - before.Counts[i]
+	}
+	return d
+}
+
+// bucketValue is the representative value of bucket i in seconds: its
+// midpoint, or the finite edge of an open-ended bucket.
+func (h *histogram) bucketValue(i int) float64 {
+	lower, upper := h.Buckets[i], h.Buckets[i+1]
+	switch {
+	case lower <= -math.MaxFloat64:
+		return upper
+	case upper >= math.MaxFloat64:
+		return lower
+	default:
+		return (lower + upper) / 2
+	}
+}
+
+func (h *histogram) total() (count uint64, sum float64) {
+	if h == nil {
+		return 0, 0
+	}
+	for i, c := range h.Counts {
+		count += c
+		sum += float64(c) * h.bucketValue(i)
+	}
+	return count, sum
+}
+
+// percentile returns the value in seconds at or below which q percent of
+// the counts fall, at bucket resolution.
+func (h *histogram) percentile(q float64) float64 {
+	count, _ := h.total()
+	if count == 0 {
+		return 0
+	}
+	target := uint64(math.Ceil(q / 100 * float64(count)))
+	if target < 1 {
+		target = 1
+	}
+	var seen uint64
+	for i, c := range h.Counts {
+		seen += c
+		if seen >= target {
+			return h.bucketValue(i)
+		}
+	}
+	return 0
+}
+
+func summarize(snapshots []*snapshot) *Result {
+	first, last := snapshots[0], snapshots[len(snapshots)-1]
+	result := &Result{
+		Samples:          len(snapshots),
+		GCCycles:         last.gcCycles - first.gcCycles,
+		HeapGoalMinBytes: math.MaxUint64,
+		GoroutinesMin:    math.MaxUint64,
+	}
+
+	if pauses := delta(first.pauses, last.pauses); pauses != nil {
+		_, sum := pauses.total()
+		result.PauseTotalMs = sum * 1e3
+		result.PauseP50Us = pauses.percentile(50) * 1e6
+		result.PauseP99Us = pauses.percentile(99) * 1e6
+		result.PauseMaxUs = pauses.percentile(100) * 1e6
+	}
+	if sched := delta(first.schedLatency, last.schedLatency); sched != nil {
+		result.SchedLatencyP50 = sched.percentile(50) * 1e6
+		result.SchedLatencyP99 = sched.percentile(99) * 1e6
+	}
+
+	for i, s := range snapshots {
+		result.HeapGoalMinBytes = min(result.HeapGoalMinBytes, s.heapGoal)
+		result.HeapGoalMaxBytes = max(result.HeapGoalMaxBytes, s.heapGoal)
+		result.GoroutinesMin = min(result.GoroutinesMin, s.goroutines)
+		result.GoroutinesMax = max(result.GoroutinesMax, s.goroutines)
+
+		if i == 0 {
+			continue
+		}
+		prev := snapshots[i-1]
+		second := GCSecond{
+			Second:        i,
+			GCCycles:      s.gcCycles - prev.gcCycles,
+			HeapGoalBytes: s.heapGoal,
+			Goroutines:    s.goroutines,
+		}
+		if pauses := delta(prev.pauses, s.pauses); pauses != nil {
+			_, sum := pauses.total()
+			second.PauseUs = sum * 1e6
+		}
+		result.Seconds = append(result.Seconds, second)
+	}
+
+	return result
+}
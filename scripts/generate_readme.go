@@ -3,8 +3,10 @@ package main
 import (
 	"encoding/json"
 	"fmt"
+	"html"
 	"io/ioutil"
 	"log"
+	"math"
 	"os"
 	"path/filepath"
 	"sort"
@@ -17,13 +19,179 @@ type BenchmarkResults struct {
 	Timestamp     string                      `json:"timestamp"`
 	Configuration BenchmarkConfig             `json:"configuration"`
 	Results       map[string][]EndpointResult `json:"results"`
+	FrameworkInfo map[string]FrameworkInfo    `json:"framework_info,omitempty"`
+	RunOrder      *RunOrder                   `json:"run_order,omitempty"`
+	Calibration   *LoadgenCalibration         `json:"loadgen_calibration,omitempty"`
+}
+
+// LoadgenCalibration is the ceiling of the load generator, measured against
+// a canned-response TCP server before the frameworks ran.
+type LoadgenCalibration struct {
+	RequestsPerSec       float64  `json:"requests_per_sec"`
+	LoadgenCPUPercent    *float64 `json:"loadgen_cpu_percent"`
+	BoundMarginPercent   float64  `json:"bound_margin_percent"`
+	CPUSaturationPercent float64  `json:"cpu_saturation_percent"`
+}
+
+// bound reports whether a throughput is within the margin of the ceiling,
+// so that it measures the load generator rather than the server.
+func (c *LoadgenCalibration) bound(rps float64) bool {
+	return c != nil && c.RequestsPerSec > 0 && rps >= c.RequestsPerSec*(1-c.BoundMarginPercent/100)
+}
+
+// RunOrder is the order the frameworks were benchmarked in. A shuffled order
+// is reproduced by passing Seed to benchmark.sh --seed.
+type RunOrder struct {
+	Seed            int64    `json:"seed"`
+	Shuffled        bool     `json:"shuffled"`
+	Interleaved     bool     `json:"interleaved"`
+	Iterations      int      `json:"iterations"`
+	CooldownSeconds int      `json:"cooldown_seconds"`
+	Sequence        []string `json:"sequence"`
+}
+
+// FrameworkInfo describes result entries: variants of a configured
+// framework, such as "go-vanilla+pgo", and the resource limits an entry ran
+// under.
+type FrameworkInfo struct {
+	Base           string          `json:"base,omitempty"`
+	Variant        string          `json:"variant,omitempty"`
+	PGOProfile     string          `json:"pgo_profile,omitempty"`
+	CPUs           int             `json:"cpus,omitempty"`
+	CPUList        string          `json:"cpu_list,omitempty"`
+	GOGC           string          `json:"gogc,omitempty"`
+	GOMemLimit     string          `json:"gomemlimit,omitempty"`
+	Iteration      int             `json:"iteration,omitempty"`
+	Baseline       bool            `json:"baseline,omitempty"`
+	ResourceLimits *ResourceLimits `json:"resource_limits,omitempty"`
+	ColdStart      *ColdStart      `json:"cold_start,omitempty"`
+	Footprint      *Footprint      `json:"footprint,omitempty"`
+	Memory         *MemoryTrend    `json:"memory,omitempty"`
+	Warmup         *Warmup         `json:"warmup,omitempty"`
+}
+
+// Warmup is how long a server was warmed up under load before its runs, and
+// how settled its throughput and P99 were at the end.
+type Warmup struct {
+	Seconds        float64 `json:"seconds"`
+	Stable         bool    `json:"stable"`
+	WindowSeconds  int     `json:"window_seconds"`
+	MaxRPSCV       float64 `json:"max_rps_cv"`
+	MaxP99CV       float64 `json:"max_p99_cv"`
+	RPSCV          float64 `json:"rps_cv"`
+	P99CV          float64 `json:"p99_cv"`
+	RequestsPerSec float64 `json:"requests_per_sec"`
+	P99Us          float64 `json:"p99_us"`
+}
+
+// MemoryTrend is the memory of a server sampled over a run with memory
+// sampling (the endurance load profile). Samples are [ms since start, RSS
+// bytes, heap in use bytes]; slopes are least-squares fits after warm-up.
+type MemoryTrend struct {
+	IntervalSeconds   float64    `json:"interval_seconds"`
+	HeapSampled       bool       `json:"heap_sampled"`
+	Samples           [][3]int64 `json:"samples"`
+	RSSSlopeMBPerMin  float64    `json:"rss_slope_mb_per_min"`
+	RSSRSquared       float64    `json:"rss_r_squared"`
+	HeapSlopeMBPerMin float64    `json:"heap_slope_mb_per_min"`
+	HeapRSquared      float64    `json:"heap_r_squared"`
+	ThresholdMBPerMin float64    `json:"threshold_mb_per_min"`
+	SuspectedLeak     bool       `json:"suspected_leak"`
+}
+
+// Footprint is what a server costs to build and ship: build time, binary
+// sizes and module counts for Go, install time and node_modules for Bun.
+type Footprint struct {
+	Runtime            string `json:"runtime"`
+	BuildTimeMs        int64  `json:"build_time_ms,omitempty"`
+	ColdBuildTimeMs    int64  `json:"cold_build_time_ms,omitempty"`
+	BinaryBytes        int64  `json:"binary_bytes,omitempty"`
+	StrippedBytes      int64  `json:"stripped_bytes,omitempty"`
+	Modules            int    `json:"modules"`
+	DirectModules      int    `json:"direct_modules"`
+	InstallTimeMs      int64  `json:"install_time_ms,omitempty"`
+	NodeModulesBytes   int64  `json:"node_modules_bytes"`
+	Packages           int    `json:"packages"`
+	DirectDependencies int    `json:"direct_dependencies"`
+}
+
+// ColdStart is measured by scripts/coldstart: spawn to first successful
+// response over several starts, and the median latency of each of the first
+// requests to the fresh process.
+type ColdStart struct {
+	Runs                 int       `json:"runs"`
+	StartupMs            []float64 `json:"startup_ms"`
+	StartupMedianMs      float64   `json:"startup_median_ms"`
+	StartupMaxMs         float64   `json:"startup_max_ms"`
+	FirstRequestUs       []int64   `json:"first_request_us"`
+	FirstRequestMedianUs int64     `json:"first_request_median_us"`
+	FirstRequestMaxUs    int64     `json:"first_request_max_us"`
+	WarmupCurveUs        []int64   `json:"warmup_curve_us"`
+}
+
+// ResourceLimits are the cgroup v2 limits a server ran under, with the
+// throttling and OOM counters of its cgroup after the run.
+type ResourceLimits struct {
+	CPUs            string `json:"cpus"`
+	Memory          string `json:"memory"`
+	NrPeriods       int64  `json:"nr_periods"`
+	NrThrottled     int64  `json:"nr_throttled"`
+	ThrottledUsec   int64  `json:"throttled_usec"`
+	OOM             int64  `json:"oom"`
+	OOMKill         int64  `json:"oom_kill"`
+	MemoryPeakBytes int64  `json:"memory_peak_bytes"`
+}
+
+// matrixVariants are variants that rerun a framework under different
+// settings. They only appear in their own sections, not in the headline
+// comparison.
+var matrixVariants = map[string]bool{"cpus": true, "gc": true, "iteration": true}
+
+func headlineResults(results *BenchmarkResults) map[string][]EndpointResult {
+	headline := make(map[string][]EndpointResult, len(results.Results))
+	for name, endpoints := range results.Results {
+		if info, ok := results.FrameworkInfo[name]; ok && matrixVariants[info.Variant] {
+			continue
+		}
+		headline[name] = endpoints
+	}
+	return headline
 }
 
 type BenchmarkConfig struct {
-	Duration    int `json:"duration"`
-	Connections int `json:"connections"`
-	Threads     int `json:"threads"`
-	WarmupTime  int `json:"warmup_time"`
+	Duration        int       `json:"duration"`
+	Connections     int       `json:"connections"`
+	Threads         int       `json:"threads"`
+	WarmupTime      int       `json:"warmup_time"`
+	WarmupMax       int       `json:"warmup_max"`
+	LoadGenerator   string    `json:"load_generator"`
+	PipelineDepth   int       `json:"pipeline_depth"`
+	NetworkProfile  string    `json:"network_profile"`
+	Profiling       bool      `json:"profiling"`
+	PGO             bool      `json:"pgo"`
+	CPUIsolation    bool      `json:"cpu_isolation"`
+	LoadGenCPUs     string    `json:"load_generator_cpus"`
+	LoadProfile     string    `json:"load_profile"`
+	ServerCPUs      string    `json:"server_cpus"`
+	ResourceProfile string    `json:"resource_profile"`
+	Host            *HostInfo `json:"host,omitempty"`
+}
+
+// HostInfo is the fingerprint of the machine a run was made on, with the
+// warnings of benchmark.sh's pre-flight noise checks.
+type HostInfo struct {
+	OS                string   `json:"os"`
+	Kernel            string   `json:"kernel"`
+	CPUModel          string   `json:"cpu_model"`
+	CPUCores          int      `json:"cpu_cores"`
+	CPUGovernor       string   `json:"cpu_governor"`
+	LoadAverage       *float64 `json:"load_average"`
+	Somaxconn         *int     `json:"somaxconn"`
+	OpenFilesLimit    *int     `json:"open_files_limit"`
+	MemoryAvailableMB *int     `json:"memory_available_mb"`
+	GoVersion         string   `json:"go_version"`
+	BunVersion        string   `json:"bun_version"`
+	Warnings          []string `json:"warnings"`
 }
 
 type EndpointResult struct {
@@ -33,14 +201,137 @@ type EndpointResult struct {
 	AvgLatency         string             `json:"avg_latency"`
 	TransferPerSec     string             `json:"transfer_per_sec"`
 	LatencyPercentiles LatencyPercentiles `json:"latency_percentiles"`
+	PercentileSpectrum []PercentilePoint  `json:"percentile_spectrum,omitempty"`
+	DistributionFile   string             `json:"distribution_file,omitempty"`
 	RawOutput          string             `json:"raw_output"`
+	LoadGenerator      string             `json:"load_generator,omitempty"`
+	PipelineDepth      int                `json:"pipeline_depth,omitempty"`
+	SlowClient         *SlowClientResult  `json:"slow_client,omitempty"`
+	Shutdown           *ShutdownResult    `json:"shutdown,omitempty"`
+	GC                 *GCTelemetry       `json:"gc,omitempty"`
+	TimingBreakdown    *TimingBreakdown   `json:"timing_breakdown,omitempty"`
+	Timeseries         []SecondBucket     `json:"timeseries,omitempty"`
+	Heatmap            *Heatmap           `json:"heatmap,omitempty"`
+	Profiles           map[string]Profile `json:"profiles,omitempty"`
+	LoadgenCPUPercent  float64            `json:"loadgen_cpu_percent,omitempty"`
+	// LoadgenBound is set by markLoadgenBound for results close to the load
+	// generator's ceiling
+	LoadgenBound bool `json:"-"`
 }
 
 type LatencyPercentiles struct {
-	P50 string `json:"50%"`
-	P75 string `json:"75%"`
-	P90 string `json:"90%"`
-	P99 string `json:"99%"`
+	P50   string `json:"50%"`
+	P75   string `json:"75%"`
+	P90   string `json:"90%"`
+	P99   string `json:"99%"`
+	P999  string `json:"99.9%,omitempty"`
+	P9999 string `json:"99.99%,omitempty"`
+	Max   string `json:"max,omitempty"`
+}
+
+type PercentilePoint struct {
+	Percentile float64 `json:"percentile"`
+	LatencyUs  int64   `json:"latency_us"`
+}
+
+type SlowClientResult struct {
+	Clients                  int               `json:"clients"`
+	Interval                 string            `json:"interval"`
+	Baseline                 PhaseSummary      `json:"baseline"`
+	UnderAttack              PhaseSummary      `json:"under_attack"`
+	ThroughputDegradationPct float64           `json:"throughput_degradation_pct"`
+	P99DegradationPct        float64           `json:"p99_degradation_pct"`
+	Connections              []SlowClientStats `json:"connections"`
+}
+
+type PhaseSummary struct {
+	RequestsPerSec string `json:"requests_per_sec"`
+	P99            string `json:"p99"`
+	Errors         int64  `json:"errors"`
+}
+
+type SlowClientStats struct {
+	Kind            string `json:"kind"`
+	Opened          int64  `json:"opened"`
+	Refused         int64  `json:"refused"`
+	Dropped         int64  `json:"dropped"`
+	Survived        int64  `json:"survived"`
+	FirstDropAfter  string `json:"first_drop_after,omitempty"`
+	MedianDropAfter string `json:"median_drop_after,omitempty"`
+	MaxDropAfter    string `json:"max_drop_after,omitempty"`
+}
+
+type ShutdownResult struct {
+	SignalAfter       string  `json:"signal_after"`
+	InFlight          int64   `json:"in_flight"`
+	InFlightCompleted int64   `json:"in_flight_completed"`
+	InFlightFailed    int64   `json:"in_flight_failed"`
+	DrainCompleted    int64   `json:"drain_completed"`
+	DrainFailed       int64   `json:"drain_failed"`
+	Refused           int64   `json:"refused"`
+	Exited            bool    `json:"exited"`
+	ExitMs            float64 `json:"exit_ms"`
+}
+
+// GCTelemetry is the Go runtime's GC and scheduler activity during an
+// endpoint run, sampled by scripts/gcwatch (--gc-telemetry).
+type GCTelemetry struct {
+	Samples          int        `json:"samples"`
+	GCCycles         uint64     `json:"gc_cycles"`
+	PauseTotalMs     float64    `json:"pause_total_ms"`
+	PauseP50Us       float64    `json:"pause_p50_us"`
+	PauseP99Us       float64    `json:"pause_p99_us"`
+	PauseMaxUs       float64    `json:"pause_max_us"`
+	HeapGoalMinBytes int64      `json:"heap_goal_min_bytes"`
+	HeapGoalMaxBytes int64      `json:"heap_goal_max_bytes"`
+	GoroutinesMin    uint64     `json:"goroutines_min"`
+	GoroutinesMax    uint64     `json:"goroutines_max"`
+	SchedLatencyP50  float64    `json:"sched_latency_p50_us"`
+	SchedLatencyP99  float64    `json:"sched_latency_p99_us"`
+	Seconds          []GCSecond `json:"seconds"`
+}
+
+type GCSecond struct {
+	Second        int     `json:"second"`
+	GCCycles      uint64  `json:"gc_cycles"`
+	PauseUs       float64 `json:"pause_us"`
+	HeapGoalBytes int64   `json:"heap_goal_bytes"`
+	Goroutines    uint64  `json:"goroutines"`
+}
+
+type TimingBreakdown struct {
+	Samples int64         `json:"samples"`
+	Errors  int64         `json:"errors"`
+	Phases  []PhaseTiming `json:"phases"`
+}
+
+type PhaseTiming struct {
+	Phase string  `json:"phase"`
+	Mean  float64 `json:"mean_us"`
+	P50   int64   `json:"p50_us"`
+	P90   int64   `json:"p90_us"`
+	P99   int64   `json:"p99_us"`
+}
+
+type SecondBucket struct {
+	Second   int   `json:"second"`
+	Requests int64 `json:"requests"`
+	Errors   int64 `json:"errors"`
+	P50      int64 `json:"p50_us"`
+	P99      int64 `json:"p99_us"`
+	Max      int64 `json:"max_us"`
+}
+
+type Heatmap struct {
+	BoundsUs []int64   `json:"bounds_us"`
+	Counts   [][]int64 `json:"counts"`
+}
+
+// Profile is a pprof profile captured during an endpoint run, with its top-N
+// function summary.
+type Profile struct {
+	File    string `json:"file"`
+	Summary string `json:"summary"`
 }
 
 type FrameworkData struct {
@@ -55,13 +346,18 @@ func main() {
 		log.Fatalf("Error loading results: %v", err)
 	}
 
-	readme := generateREADME(results)
-
 	outputFile := "README.md"
 	if len(os.Args) > 1 {
 		outputFile = os.Args[1]
 	}
 
+	heatmaps, err := writeHeatmaps(results, filepath.Dir(outputFile))
+	if err != nil {
+		log.Fatalf("Error writing heatmaps: %v", err)
+	}
+
+	readme := generateREADME(results, heatmaps, filepath.Dir(outputFile))
+
 	err = ioutil.WriteFile(outputFile, []byte(readme), 0644)
 	if err != nil {
 		log.Fatalf("Error writing README: %v", err)
@@ -112,9 +408,35 @@ func loadLatestResults() (*BenchmarkResults, error) {
 		return nil, err
 	}
 
+	results.Results = filterPipelineDepth(results.Results, pipelineDepth(results.Configuration.PipelineDepth))
+
 	return &results, nil
 }
 
+// pipelineDepth normalizes a recorded pipeline depth; results written before
+// pipelining existed (and all wrk runs) carry no depth and mean 1.
+func pipelineDepth(depth int) int {
+	if depth < 1 {
+		return 1
+	}
+	return depth
+}
+
+// filterPipelineDepth drops endpoint results measured at a different
+// pipelining depth than the run, so pipelined and non-pipelined numbers never
+// end up in the same table.
+func filterPipelineDepth(results map[string][]EndpointResult, depth int) map[string][]EndpointResult {
+	filtered := make(map[string][]EndpointResult, len(results))
+	for framework, endpoints := range results {
+		for _, endpoint := range endpoints {
+			if pipelineDepth(endpoint.PipelineDepth) == depth {
+				filtered[framework] = append(filtered[framework], endpoint)
+			}
+		}
+	}
+	return filtered
+}
+
 func formatNumber(value string) string {
 	// Remove commas and parse
 	cleanValue := strings.ReplaceAll(value, ",", "")
@@ -165,9 +487,45 @@ func parseRPS(rpsStr string) float64 {
 	return 0
 }
 
+// orDash fills table cells for values that older results do not have.
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+// parseLatency converts a wrk-style latency ("950.00us", "1.23ms", "2.1s")
+// to microseconds.
+func parseLatency(latency string) float64 {
+	units := []struct {
+		Suffix string
+		Scale  float64
+	}{{"us", 1}, {"ms", 1e3}, {"s", 1e6}}
+	for _, u := range units {
+		if strings.HasSuffix(latency, u.Suffix) {
+			value, err := strconv.ParseFloat(strings.TrimSuffix(latency, u.Suffix), 64)
+			if err != nil {
+				return 0
+			}
+			return value * u.Scale
+		}
+	}
+	return 0
+}
+
+// percentChange formats the change from before to after, or "-" when there
+// is nothing to compare against.
+func percentChange(before, after float64) string {
+	if before == 0 || after == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%+.1f%%", (after-before)/before*100)
+}
+
 func createPerformanceTable(results map[string][]EndpointResult) string {
-	table := "\n| Framework | Requests/sec | Avg Latency | P50 | P75 | P90 | P99 |\n"
-	table += "|-----------|-------------|-------------|-----|-----|-----|-----|\n"
+	table := "\n| Framework | Requests/sec | Avg Latency | P50 | P75 | P90 | P99 | P99.9 | P99.99 | Max |\n"
+	table += "|-----------|-------------|-------------|-----|-----|-----|-----|-------|--------|-----|\n"
 
 	// Collect and sort frameworks by RPS
 	var frameworks []FrameworkData
@@ -192,28 +550,278 @@ func createPerformanceTable(results map[string][]EndpointResult) string {
 
 	for _, fw := range frameworks {
 		name := strings.Title(strings.ReplaceAll(fw.Name, "-", " "))
-		table += fmt.Sprintf("| **%s** | %s | %s | %s | %s | %s | %s |\n",
+		table += fmt.Sprintf("| **%s** | %s | %s | %s | %s | %s | %s | %s | %s | %s |\n",
 			name,
-			formatNumber(fw.Data.RequestsPerSec),
+			formatNumber(fw.Data.RequestsPerSec)+boundMark(fw.Data),
 			fw.Data.AvgLatency,
 			fw.Data.LatencyPercentiles.P50,
 			fw.Data.LatencyPercentiles.P75,
 			fw.Data.LatencyPercentiles.P90,
 			fw.Data.LatencyPercentiles.P99,
+			orDash(fw.Data.LatencyPercentiles.P999),
+			orDash(fw.Data.LatencyPercentiles.P9999),
+			orDash(fw.Data.LatencyPercentiles.Max),
 		)
 	}
 
 	return table
 }
 
+// markLoadgenBound marks the results within the bound margin of the load
+// generator's ceiling.
+func markLoadgenBound(results *BenchmarkResults) {
+	for _, endpoints := range results.Results {
+		for i := range endpoints {
+			endpoints[i].LoadgenBound = results.Calibration.bound(parseRPS(endpoints[i].RequestsPerSec))
+		}
+	}
+}
+
+func boundMark(endpoint EndpointResult) string {
+	if endpoint.LoadgenBound {
+		return " ⚠️"
+	}
+	return ""
+}
+
+// calibrationNotice explains the ⚠️ of load-generator bound results, if any.
+func calibrationNotice(results *BenchmarkResults) string {
+	for _, endpoints := range results.Results {
+		for _, endpoint := range endpoints {
+			if endpoint.LoadgenBound {
+				return fmt.Sprintf("\n> ⚠️ Results marked ⚠️ are **load-generator bound**: within %.0f%% of the %s req/s the load generator reaches against a canned-response server, so they show the limit of the load generator, not of the framework.\n",
+					results.Calibration.BoundMarginPercent, formatNumber(fmt.Sprintf("%.0f", results.Calibration.RequestsPerSec)))
+			}
+		}
+	}
+	return ""
+}
+
+// createBaselineSection expresses every framework's throughput as a share of
+// the baseline server's (raw-tcp), which answers with canned bytes and no
+// HTTP parser, to make the cost of each HTTP stack explicit.
+func createBaselineSection(results *BenchmarkResults, headline map[string][]EndpointResult) string {
+	baseline := ""
+	for name, info := range results.FrameworkInfo {
+		if info.Baseline && len(headline[name]) > 0 {
+			baseline = name
+			break
+		}
+	}
+	if baseline == "" {
+		return ""
+	}
+
+	endpoints := []string{"Root endpoint", "Health check", "User endpoint", "POST users"}
+	baselineRPS := make(map[string]float64)
+	for _, endpoint := range headline[baseline] {
+		baselineRPS[endpoint.Endpoint] = parseRPS(endpoint.RequestsPerSec)
+	}
+
+	var frameworks []FrameworkData
+	for name, runs := range headline {
+		if name == baseline {
+			continue
+		}
+		for _, endpoint := range runs {
+			if endpoint.Endpoint == "Root endpoint" {
+				frameworks = append(frameworks, FrameworkData{Name: name, RPS: parseRPS(endpoint.RequestsPerSec)})
+				break
+			}
+		}
+	}
+	sort.Slice(frameworks, func(i, j int) bool {
+		return frameworks[i].RPS > frameworks[j].RPS
+	})
+
+	baselineName := strings.Title(strings.ReplaceAll(baseline, "-", " "))
+	section := "\n## 🧱 Share of the Raw TCP Baseline\n\n" +
+		fmt.Sprintf("**%s** writes precomputed responses straight to the TCP connection, without an HTTP parser, router or JSON encoder: ", baselineName) +
+		"the most this machine can serve. Each framework's throughput as a share of it shows what its HTTP stack and handlers cost.\n\n" +
+		"| Framework | / | /health | /user/123 | POST /users |\n" +
+		"|-----------|---|---------|-----------|-------------|\n"
+
+	baselineRow := fmt.Sprintf("| **%s** |", baselineName)
+	for _, endpoint := range endpoints {
+		baselineRow += fmt.Sprintf(" %s req/s |", formatNumber(fmt.Sprintf("%.0f", baselineRPS[endpoint])))
+	}
+	section += baselineRow + "\n"
+
+	for _, fw := range frameworks {
+		row := fmt.Sprintf("| **%s** |", strings.Title(strings.ReplaceAll(fw.Name, "-", " ")))
+		for _, name := range endpoints {
+			share := "-"
+			for _, endpoint := range headline[fw.Name] {
+				if endpoint.Endpoint == name && baselineRPS[name] > 0 {
+					share = fmt.Sprintf("%.0f%%", parseRPS(endpoint.RequestsPerSec)/baselineRPS[name]*100)
+					break
+				}
+			}
+			row += " " + share + " |"
+		}
+		section += row + "\n"
+	}
+
+	return section
+}
+
+// createHandlerGapSection splits the throughput gap between go-vanilla and
+// go-fiber into the part go-vanilla-optimized closes with the same net/http
+// server, which is handler code, and the rest, which is the framework.
+func createHandlerGapSection(results map[string][]EndpointResult) string {
+	const vanilla, optimized, fiber = "go-vanilla", "go-vanilla-optimized", "go-fiber"
+	if len(results[vanilla]) == 0 || len(results[optimized]) == 0 || len(results[fiber]) == 0 {
+		return ""
+	}
+
+	rps := func(framework, endpoint string) float64 {
+		for _, result := range results[framework] {
+			if result.Endpoint == endpoint {
+				return parseRPS(result.RequestsPerSec)
+			}
+		}
+		return 0
+	}
+
+	section := "\n## 🧩 Handler Code vs Framework\n\n" +
+		"Go Vanilla Optimized runs the same `net/http` server as Go Vanilla with zero-allocation handlers. " +
+		"The share of the Go Vanilla to Go Fiber gap it closes is the cost of idiomatic handler code; the rest is the framework (fasthttp vs net/http).\n\n" +
+		"| Endpoint | Go Vanilla | Go Vanilla Optimized | Go Fiber | Gap Closed by Handler Code |\n" +
+		"|----------|------------|----------------------|----------|----------------------------|\n"
+
+	for _, endpoint := range []string{"Root endpoint", "Health check", "User endpoint", "POST users"} {
+		v, o, f := rps(vanilla, endpoint), rps(optimized, endpoint), rps(fiber, endpoint)
+		if v == 0 || o == 0 || f == 0 {
+			continue
+		}
+		closed := "- (Go Fiber is not faster)"
+		if f > v {
+			closed = fmt.Sprintf("%.0f%%", (o-v)/(f-v)*100)
+		}
+		section += fmt.Sprintf("| %s | %s | %s | %s | %s |\n",
+			endpoint,
+			formatNumber(fmt.Sprintf("%.0f", v)),
+			formatNumber(fmt.Sprintf("%.0f", o)),
+			formatNumber(fmt.Sprintf("%.0f", f)),
+			closed,
+		)
+	}
+
+	return section
+}
+
+// createRoutingSection compares go-vanilla, which parses paths and checks
+// methods by hand, with go-vanilla-mux122, which routes with Go 1.22
+// ServeMux patterns, to show what the pattern router costs.
+func createRoutingSection(results map[string][]EndpointResult) string {
+	const manual, patterns = "go-vanilla", "go-vanilla-mux122"
+	if len(results[manual]) == 0 || len(results[patterns]) == 0 {
+		return ""
+	}
+
+	section := "\n## 🧭 Routing: Manual vs Go 1.22 Patterns\n\n" +
+		"Go Vanilla routes with `mux.HandleFunc(\"/user/\", ...)`, slicing the ID out of the path and checking the method in every handler. " +
+		"Go Vanilla Mux122 registers `GET /user/{id}` and reads the ID with `r.PathValue`; the handlers are otherwise identical. " +
+		"Off the benchmarked requests the two differ: `GET` patterns also match `HEAD`, which Go Vanilla answers with 405, and unknown paths get a 404 instead of falling through to `/`.\n\n" +
+		"| Endpoint | Manual Routing | Go 1.22 Patterns | Change | P99 Manual | P99 Patterns |\n" +
+		"|----------|----------------|------------------|--------|------------|--------------|\n"
+
+	for _, endpoint := range []string{"Root endpoint", "Health check", "User endpoint", "POST users"} {
+		var before, after *EndpointResult
+		for i := range results[manual] {
+			if results[manual][i].Endpoint == endpoint {
+				before = &results[manual][i]
+			}
+		}
+		for i := range results[patterns] {
+			if results[patterns][i].Endpoint == endpoint {
+				after = &results[patterns][i]
+			}
+		}
+		if before == nil || after == nil {
+			continue
+		}
+
+		section += fmt.Sprintf("| %s | %s | %s | %s | %s | %s |\n",
+			endpoint,
+			formatNumber(before.RequestsPerSec),
+			formatNumber(after.RequestsPerSec),
+			percentChange(parseRPS(before.RequestsPerSec), parseRPS(after.RequestsPerSec)),
+			orDash(before.LatencyPercentiles.P99),
+			orDash(after.LatencyPercentiles.P99),
+		)
+	}
+
+	return section
+}
+
+// createCalibrationSection compares every result with the ceiling of the
+// load generator and shows how busy the load generator was.
+func createCalibrationSection(results *BenchmarkResults) string {
+	calibration := results.Calibration
+	if calibration == nil || calibration.RequestsPerSec <= 0 {
+		return ""
+	}
+
+	var frameworks []string
+	for name := range results.Results {
+		frameworks = append(frameworks, name)
+	}
+	sort.Strings(frameworks)
+
+	ceilingCPU := "-"
+	if calibration.LoadgenCPUPercent != nil {
+		ceilingCPU = fmt.Sprintf("%.0f%%", *calibration.LoadgenCPUPercent)
+	}
+	section := "\n## 🎯 Load Generator Ceiling\n\n" +
+		fmt.Sprintf("Against a TCP server answering every request with canned bytes, the load generator reached **%s req/s** using %s of its CPUs. ",
+			formatNumber(fmt.Sprintf("%.0f", calibration.RequestsPerSec)), ceilingCPU) +
+		fmt.Sprintf("Results within %.0f%% of that ceiling are load-generator bound: a faster server would not score higher. ", calibration.BoundMarginPercent) +
+		fmt.Sprintf("Load generator CPU is the share of its CPUs it used during the run; at %.0f%% or more it is saturated.\n\n", calibration.CPUSaturationPercent) +
+		"| Framework | Endpoint | Requests/sec | % of Ceiling | Load Generator CPU | Verdict |\n" +
+		"|-----------|----------|--------------|--------------|--------------------|---------|\n"
+
+	for _, name := range frameworks {
+		for _, endpoint := range results.Results[name] {
+			rps := parseRPS(endpoint.RequestsPerSec)
+			if rps == 0 {
+				continue
+			}
+
+			cpu := "-"
+			if endpoint.LoadgenCPUPercent > 0 {
+				cpu = fmt.Sprintf("%.0f%%", endpoint.LoadgenCPUPercent)
+			}
+			verdict := "✅ Server bound"
+			switch {
+			case endpoint.LoadgenBound:
+				verdict = "⚠️ Load-generator bound"
+			case endpoint.LoadgenCPUPercent >= calibration.CPUSaturationPercent:
+				verdict = "⚠️ Load generator saturated"
+			}
+
+			section += fmt.Sprintf("| **%s** | %s | %s | %.0f%% | %s | %s |\n",
+				strings.Title(strings.ReplaceAll(name, "-", " ")),
+				endpoint.Endpoint,
+				formatNumber(endpoint.RequestsPerSec),
+				rps/calibration.RequestsPerSec*100,
+				cpu,
+				verdict,
+			)
+		}
+	}
+
+	return section
+}
+
 func createEndpointComparison(results map[string][]EndpointResult) string {
 	endpointsToCompare := []string{"Root endpoint", "Health check", "User endpoint", "POST users"}
 	comparison := ""
 
 	for _, endpointName := range endpointsToCompare {
 		comparison += fmt.Sprintf("\n### %s\n\n", endpointName)
-		comparison += "| Framework | Requests/sec | Avg Latency |\n"
-		comparison += "|-----------|-------------|-------------|\n"
+		comparison += "| Framework | Requests/sec | Avg Latency | P99 | P99.9 | P99.99 | Max |\n"
+		comparison += "|-----------|-------------|-------------|-----|-------|--------|-----|\n"
 
 		// Collect data for this endpoint
 		var endpointData []FrameworkData
@@ -239,10 +847,14 @@ func createEndpointComparison(results map[string][]EndpointResult) string {
 
 		for _, fw := range endpointData {
 			name := strings.Title(strings.ReplaceAll(fw.Name, "-", " "))
-			comparison += fmt.Sprintf("| **%s** | %s | %s |\n",
+			comparison += fmt.Sprintf("| **%s** | %s | %s | %s | %s | %s | %s |\n",
 				name,
-				formatNumber(fw.Data.RequestsPerSec),
+				formatNumber(fw.Data.RequestsPerSec)+boundMark(fw.Data),
 				fw.Data.AvgLatency,
+				orDash(fw.Data.LatencyPercentiles.P99),
+				orDash(fw.Data.LatencyPercentiles.P999),
+				orDash(fw.Data.LatencyPercentiles.P9999),
+				orDash(fw.Data.LatencyPercentiles.Max),
 			)
 		}
 	}
@@ -292,58 +904,1508 @@ func createASCIIChart(results map[string][]EndpointResult) string {
 	return chart
 }
 
-func generateREADME(results *BenchmarkResults) string {
-	if results == nil || len(results.Results) == 0 {
-		return "# Benchmark Results\n\nNo benchmark data available. Run `./scripts/benchmark.sh` to generate results."
+func createSlowClientSection(results map[string][]EndpointResult) string {
+	var frameworks []FrameworkData
+	for framework, endpoints := range results {
+		for _, endpoint := range endpoints {
+			if endpoint.SlowClient != nil {
+				frameworks = append(frameworks, FrameworkData{
+					Name: framework,
+					RPS:  parseRPS(endpoint.RequestsPerSec),
+					Data: endpoint,
+				})
+				break
+			}
+		}
 	}
 
-	readme := fmt.Sprintf(`# JS vs Go Web Framework Benchmark
+	if len(frameworks) == 0 {
+		return ""
+	}
 
-A comprehensive performance comparison between JavaScript (Bun) and Go web frameworks.
+	sort.Slice(frameworks, func(i, j int) bool {
+		return frameworks[i].RPS > frameworks[j].RPS
+	})
 
-## 🚀 Quick Results
+	slow := frameworks[0].Data.SlowClient
+	section := "\n## 🐌 Slow-Client Resilience\n\n"
+	section += fmt.Sprintf("Legitimate load on `/` measured alone and again with %d slow clients attached (trickling headers, trickling a POST body, or reading responses one byte every %s).\n\n",
+		slow.Clients, slow.Interval)
+	section += "| Framework | Baseline RPS | Under Attack RPS | Throughput Loss | Baseline P99 | Under Attack P99 | P99 Change | Slow Connections Dropped |\n"
+	section += "|-----------|--------------|------------------|-----------------|--------------|------------------|------------|--------------------------|\n"
 
-%s
+	for _, fw := range frameworks {
+		s := fw.Data.SlowClient
+		var drops []string
+		for _, c := range s.Connections {
+			if c.Dropped > 0 {
+				drops = append(drops, fmt.Sprintf("%s: %d/%d after ~%s", c.Kind, c.Dropped, c.Opened, c.MedianDropAfter))
+			} else {
+				drops = append(drops, fmt.Sprintf("%s: never", c.Kind))
+			}
+		}
 
-## 📊 Performance Chart
+		name := strings.Title(strings.ReplaceAll(fw.Name, "-", " "))
+		section += fmt.Sprintf("| **%s** | %s | %s | %.1f%% | %s | %s | %+.1f%% | %s |\n",
+			name,
+			formatNumber(s.Baseline.RequestsPerSec),
+			formatNumber(s.UnderAttack.RequestsPerSec),
+			s.ThroughputDegradationPct,
+			s.Baseline.P99,
+			s.UnderAttack.P99,
+			s.P99DegradationPct,
+			strings.Join(drops, ", "),
+		)
+	}
 
-%s
+	return section
+}
 
-## 🔧 Frameworks Tested
+func createShutdownSection(results map[string][]EndpointResult) string {
+	var frameworks []string
+	shutdowns := make(map[string]*ShutdownResult)
+	for framework, endpoints := range results {
+		for _, endpoint := range endpoints {
+			if endpoint.Shutdown != nil {
+				frameworks = append(frameworks, framework)
+				shutdowns[framework] = endpoint.Shutdown
+				break
+			}
+		}
+	}
 
-### Go Frameworks
-- **Go Vanilla (net/http)**: Standard Go HTTP server using the built-in `+"`net/http`"+` package
-- **Go Fiber**: Fast Express-inspired web framework built on top of Fasthttp
+	if len(frameworks) == 0 {
+		return ""
+	}
+	sort.Strings(frameworks)
+
+	section := "\n## 🛑 Graceful Shutdown\n\n" +
+		fmt.Sprintf("Each server was sent SIGTERM %s into a run on `/`. ", shutdowns[frameworks[0]].SignalAfter) +
+		"In-flight requests were written before the signal; requests after it were sent on connections still open while the server drained. " +
+		"Refused counts the connections the load generator could not open after the signal, each once however often it retried.\n\n" +
+		"| Framework | In-Flight Completed | In-Flight Failed | Completed While Draining | Failed While Draining | Refused | Time to Exit |\n" +
+		"|-----------|---------------------|------------------|--------------------------|-----------------------|---------|--------------|\n"
+
+	for _, framework := range frameworks {
+		s := shutdowns[framework]
+		exit := fmt.Sprintf("%.1fms", s.ExitMs)
+		if !s.Exited {
+			exit = fmt.Sprintf("⚠️ still running after %.1fs", s.ExitMs/1000)
+		}
+		section += fmt.Sprintf("| **%s** | %d / %d | %d | %d | %d | %d | %s |\n",
+			strings.Title(strings.ReplaceAll(framework, "-", " ")),
+			s.InFlightCompleted,
+			s.InFlight,
+			s.InFlightFailed,
+			s.DrainCompleted,
+			s.DrainFailed,
+			s.Refused,
+			exit,
+		)
+	}
 
-### JavaScript/TypeScript Frameworks (Bun Runtime)
-- **Bun Vanilla**: Pure Bun HTTP server using Bun's native HTTP APIs
-- **Hono.js**: Ultrafast web framework for Cloudflare Workers, Deno, Bun, and Node.js
+	return section
+}
 
-## 📈 Detailed Results by Endpoint
+// timingPhaseGlyphs draws each request phase in the stacked breakdown bars.
+var timingPhaseGlyphs = []struct {
+	Phase string
+	Label string
+	Glyph string
+}{
+	{"dns", "DNS", "·"},
+	{"connect", "Connect", "░"},
+	{"write", "Write", "▒"},
+	{"ttfb", "Server (TTFB)", "█"},
+	{"body", "Body", "▓"},
+}
 
-%s
+// formatMicros renders a microsecond value the way wrk prints latencies.
+func formatMicros(us float64) string {
+	switch {
+	case us >= 1e6:
+		return fmt.Sprintf("%.2fs", us/1e6)
+	case us >= 1e3:
+		return fmt.Sprintf("%.2fms", us/1e3)
+	default:
+		return fmt.Sprintf("%.2fus", us)
+	}
+}
 
-## ⚙️ Benchmark Configuration
+func phaseTiming(b *TimingBreakdown, phase string) PhaseTiming {
+	for _, p := range b.Phases {
+		if p.Phase == phase {
+			return p
+		}
+	}
+	return PhaseTiming{Phase: phase}
+}
 
-- **Duration**: %d seconds
-- **Connections**: %d
-- **Threads**: %d
-- **Warmup Time**: %d seconds
-- **Tool**: [wrk](https://github.com/wg/wrk)
-- **Last Updated**: %s
+func createTimingBreakdown(results map[string][]EndpointResult) string {
+	endpointsToCompare := []string{"Root endpoint", "Health check", "User endpoint", "POST users"}
+	section := ""
 
-## 🛠️ Setup & Running
+	for _, endpointName := range endpointsToCompare {
+		var endpointData []FrameworkData
+		for framework, endpoints := range results {
+			for _, endpoint := range endpoints {
+				if endpoint.Endpoint == endpointName && endpoint.TimingBreakdown != nil && endpoint.TimingBreakdown.Samples > 0 {
+					endpointData = append(endpointData, FrameworkData{
+						Name: framework,
+						RPS:  parseRPS(endpoint.RequestsPerSec),
+						Data: endpoint,
+					})
+					break
+				}
+			}
+		}
 
-### Prerequisites
+		if len(endpointData) == 0 {
+			continue
+		}
 
-`+"```bash"+`
-# Install Go
-brew install go
+		sort.Slice(endpointData, func(i, j int) bool {
+			return endpointData[i].RPS > endpointData[j].RPS
+		})
 
-# Install Bun
-curl -fsSL https://bun.sh/install | bash
+		// Means add up to the mean total, so they stack; percentiles do not
+		maxTotal := 0.0
+		for _, fw := range endpointData {
+			total := 0.0
+			for _, p := range fw.Data.TimingBreakdown.Phases {
+				total += p.Mean
+			}
+			if total > maxTotal {
+				maxTotal = total
+			}
+		}
 
-# Install wrk (macOS)
+		section += fmt.Sprintf("\n### %s\n\n```\n", endpointName)
+		for _, fw := range endpointData {
+			bar := ""
+			total := 0.0
+			for _, g := range timingPhaseGlyphs {
+				mean := phaseTiming(fw.Data.TimingBreakdown, g.Phase).Mean
+				total += mean
+				if maxTotal > 0 {
+					bar += strings.Repeat(g.Glyph, int(mean/maxTotal*50+0.5))
+				}
+			}
+			section += fmt.Sprintf("%-12s │%s %s\n", fw.Name, bar, formatMicros(total))
+		}
+		section += "```\n\n"
+
+		section += "| Framework | Samples |"
+		separator := "|-----------|---------|"
+		for _, g := range timingPhaseGlyphs {
+			section += fmt.Sprintf(" %s P50 / P99 |", g.Label)
+			separator += "-----------|"
+		}
+		section += "\n" + separator + "\n"
+
+		for _, fw := range endpointData {
+			name := strings.Title(strings.ReplaceAll(fw.Name, "-", " "))
+			section += fmt.Sprintf("| **%s** | %d |", name, fw.Data.TimingBreakdown.Samples)
+			for _, g := range timingPhaseGlyphs {
+				p := phaseTiming(fw.Data.TimingBreakdown, g.Phase)
+				section += fmt.Sprintf(" %s / %s |", formatMicros(float64(p.P50)), formatMicros(float64(p.P99)))
+			}
+			section += "\n"
+		}
+	}
+
+	if section == "" {
+		return ""
+	}
+
+	legend := make([]string, 0, len(timingPhaseGlyphs))
+	for _, g := range timingPhaseGlyphs {
+		legend = append(legend, fmt.Sprintf("`%s` %s", g.Glyph, g.Label))
+	}
+
+	return "\n## ⏱️ Request Timing Breakdown\n\n" +
+		"Mean time per phase for requests sampled with `net/http/httptrace` on fresh connections, stacked per framework. " +
+		"Server (TTFB) runs from the request being written to the first response byte.\n\n" +
+		"Legend: " + strings.Join(legend, " · ") + "\n" + section
+}
+
+// sparkTicks are the eight bar heights of a sparkline, lowest first.
+var sparkTicks = []rune("▁▂▃▄▅▆▇█")
+
+// maxSparkColumns caps the width of a time-series sparkline; longer runs are
+// folded so that each column covers several seconds.
+const maxSparkColumns = 60
+
+// sparkline draws values scaled from zero to the largest value.
+func sparkline(values []float64) string {
+	maxValue := 0.0
+	for _, v := range values {
+		if v > maxValue {
+			maxValue = v
+		}
+	}
+
+	var b strings.Builder
+	for _, v := range values {
+		i := 0
+		if maxValue > 0 {
+			i = int(v / maxValue * float64(len(sparkTicks)-1))
+		}
+		b.WriteRune(sparkTicks[i])
+	}
+	return b.String()
+}
+
+// foldSeconds reduces a time series to at most maxSparkColumns columns.
+// Throughput is averaged over the folded seconds, while P99 and errors keep
+// their worst value so that short stalls stay visible.
+func foldSeconds(series []SecondBucket) (rps, p99, errors []float64) {
+	width := (len(series) + maxSparkColumns - 1) / maxSparkColumns
+	for start := 0; start < len(series); start += width {
+		end := start + width
+		if end > len(series) {
+			end = len(series)
+		}
+
+		var requests, worstP99, worstErrors float64
+		for _, s := range series[start:end] {
+			requests += float64(s.Requests)
+			worstP99 = math.Max(worstP99, float64(s.P99))
+			worstErrors = math.Max(worstErrors, float64(s.Errors))
+		}
+		rps = append(rps, requests/float64(end-start))
+		p99 = append(p99, worstP99)
+		errors = append(errors, worstErrors)
+	}
+	return rps, p99, errors
+}
+
+func createTimeseriesSection(results map[string][]EndpointResult) string {
+	endpointsToCompare := []string{"Root endpoint", "Health check", "User endpoint", "POST users"}
+	section := ""
+
+	for _, endpointName := range endpointsToCompare {
+		var endpointData []FrameworkData
+		for framework, endpoints := range results {
+			for _, endpoint := range endpoints {
+				if endpoint.Endpoint == endpointName && len(endpoint.Timeseries) > 0 {
+					endpointData = append(endpointData, FrameworkData{
+						Name: framework,
+						RPS:  parseRPS(endpoint.RequestsPerSec),
+						Data: endpoint,
+					})
+					break
+				}
+			}
+		}
+
+		if len(endpointData) == 0 {
+			continue
+		}
+
+		sort.Slice(endpointData, func(i, j int) bool {
+			return endpointData[i].RPS > endpointData[j].RPS
+		})
+
+		section += fmt.Sprintf("\n### %s\n\n```\n", endpointName)
+		for _, fw := range endpointData {
+			series := fw.Data.Timeseries
+			rps, p99, errors := foldSeconds(series)
+
+			minRPS, maxRPS := math.Inf(1), 0.0
+			for _, v := range rps {
+				minRPS = math.Min(minRPS, v)
+				maxRPS = math.Max(maxRPS, v)
+			}
+			worstP99, totalErrors := 0.0, int64(0)
+			for _, s := range series {
+				worstP99 = math.Max(worstP99, float64(s.P99))
+				totalErrors += s.Errors
+			}
+
+			section += fmt.Sprintf("%-12s RPS    │%s│ %s – %s req/s\n", fw.Name, sparkline(rps),
+				formatNumber(fmt.Sprintf("%.0f", minRPS)), formatNumber(fmt.Sprintf("%.0f", maxRPS)))
+			section += fmt.Sprintf("%-12s P99    │%s│ peak %s\n", "", sparkline(p99), formatMicros(worstP99))
+			if totalErrors > 0 {
+				section += fmt.Sprintf("%-12s Errors │%s│ %d total\n", "", sparkline(errors), totalErrors)
+			}
+		}
+		section += "```\n"
+	}
+
+	if section == "" {
+		return ""
+	}
+
+	return "\n## 📉 Throughput & Latency Over Time\n\n" +
+		"Per-second requests and P99 latency recorded by the load generator, each line scaled from zero to its own peak. " +
+		"Dips, spikes and drift that the averages above hide show up here.\n" + section
+}
+
+// gcLatencyCorrelation lines up the per-second GC pauses with the load
+// generator's per-second P99. It returns the Pearson correlation of the two
+// (NaN when either is flat) and how many latency spikes, seconds with a P99
+// above twice the median, had a GC cycle in the same second.
+func gcLatencyCorrelation(series []SecondBucket, gc []GCSecond) (r float64, spikesWithGC, spikes int) {
+	cycles := make(map[int]GCSecond, len(gc))
+	for _, g := range gc {
+		cycles[g.Second] = g
+	}
+
+	p99s := make([]float64, 0, len(series))
+	for _, s := range series {
+		p99s = append(p99s, float64(s.P99))
+	}
+	sort.Float64s(p99s)
+	median := 0.0
+	if len(p99s) > 0 {
+		median = p99s[len(p99s)/2]
+	}
+
+	var n, sx, sy, sxx, syy, sxy float64
+	for _, s := range series {
+		g, ok := cycles[s.Second]
+		if !ok {
+			continue
+		}
+		x, y := g.PauseUs, float64(s.P99)
+		n++
+		sx += x
+		sy += y
+		sxx += x * x
+		syy += y * y
+		sxy += x * y
+
+		if median > 0 && y > 2*median {
+			spikes++
+			if g.GCCycles > 0 {
+				spikesWithGC++
+			}
+		}
+	}
+
+	r = math.NaN()
+	if spread := (n*sxx - sx*sx) * (n*syy - sy*sy); n >= 3 && spread > 0 {
+		r = (n*sxy - sx*sy) / math.Sqrt(spread)
+	}
+	return r, spikesWithGC, spikes
+}
+
+func createGCSection(results map[string][]EndpointResult) string {
+	endpointsToCompare := []string{"Root endpoint", "Health check", "User endpoint", "POST users"}
+
+	var frameworks []string
+	for framework, endpoints := range results {
+		for _, endpoint := range endpoints {
+			if endpoint.GC != nil {
+				frameworks = append(frameworks, framework)
+				break
+			}
+		}
+	}
+
+	if len(frameworks) == 0 {
+		return ""
+	}
+	sort.Strings(frameworks)
+
+	section := "\n## ♻️ Go Runtime: GC & Scheduler\n\n" +
+		"Garbage collector and scheduler activity of the Go servers during each run, read from `runtime/metrics`. " +
+		"Pause times are stop-the-world pauses at histogram-bucket resolution; scheduler latency is how long runnable goroutines waited for a thread. " +
+		"Where the load generator recorded per-second latency, *r* is the correlation of each second's GC pause time with its P99, " +
+		"and *spikes with GC* counts the seconds with a P99 above twice the median that also ran a GC cycle.\n\n" +
+		"| Framework | Endpoint | GC Cycles | Paused | Pause P99 | Pause Max | Heap Goal | Goroutines | Sched P99 | r (pause, P99) | Spikes with GC |\n" +
+		"|-----------|----------|-----------|--------|-----------|-----------|-----------|------------|-----------|----------------|----------------|\n"
+
+	for _, framework := range frameworks {
+		name := strings.Title(strings.ReplaceAll(framework, "-", " "))
+		for _, endpointName := range endpointsToCompare {
+			for _, endpoint := range results[framework] {
+				if endpoint.Endpoint != endpointName || endpoint.GC == nil {
+					continue
+				}
+				gc := endpoint.GC
+
+				heapGoal := formatMB(gc.HeapGoalMaxBytes)
+				if gc.HeapGoalMinBytes != gc.HeapGoalMaxBytes {
+					heapGoal = formatMB(gc.HeapGoalMinBytes) + " – " + heapGoal
+				}
+
+				correlation, spikes := "-", "-"
+				if len(endpoint.Timeseries) > 0 {
+					r, withGC, total := gcLatencyCorrelation(endpoint.Timeseries, gc.Seconds)
+					if !math.IsNaN(r) {
+						correlation = fmt.Sprintf("%+.2f", r)
+					}
+					spikes = fmt.Sprintf("%d / %d", withGC, total)
+				}
+
+				section += fmt.Sprintf("| **%s** | %s | %d | %.2fms | %s | %s | %s | %d | %s | %s | %s |\n",
+					name,
+					endpointName,
+					gc.GCCycles,
+					gc.PauseTotalMs,
+					formatMicros(gc.PauseP99Us),
+					formatMicros(gc.PauseMaxUs),
+					heapGoal,
+					gc.GoroutinesMax,
+					formatMicros(gc.SchedLatencyP99),
+					correlation,
+					spikes,
+				)
+				break
+			}
+		}
+	}
+
+	return section
+}
+
+const heatmapDir = "./results/heatmaps"
+
+// relativeLink turns a path relative to the working directory into a link
+// relative to the README being generated.
+func relativeLink(readmeDir, path string) string {
+	absReadme, errReadme := filepath.Abs(readmeDir)
+	absPath, errPath := filepath.Abs(path)
+	if errReadme == nil && errPath == nil {
+		if rel, err := filepath.Rel(absReadme, absPath); err == nil {
+			return filepath.ToSlash(rel)
+		}
+	}
+	return filepath.ToSlash(path)
+}
+
+// heatmapKey identifies the heatmap of one framework and endpoint.
+func heatmapKey(framework, endpoint string) string {
+	return framework + "|" + endpoint
+}
+
+// writeHeatmaps renders an SVG heatmap for every endpoint result that has
+// one into ./results/heatmaps and returns the image paths, relative to
+// readmeDir, by heatmapKey.
+func writeHeatmaps(results *BenchmarkResults, readmeDir string) (map[string]string, error) {
+	heatmaps := make(map[string]string)
+	if results == nil {
+		return heatmaps, nil
+	}
+
+	// Heatmaps always describe the latest run
+	stale, _ := filepath.Glob(filepath.Join(heatmapDir, "*.svg"))
+	for _, file := range stale {
+		os.Remove(file)
+	}
+
+	for framework, endpoints := range results.Results {
+		for _, endpoint := range endpoints {
+			if endpoint.Heatmap == nil || len(endpoint.Heatmap.Counts) == 0 {
+				continue
+			}
+
+			if err := os.MkdirAll(heatmapDir, 0755); err != nil {
+				return nil, err
+			}
+			slug := strings.ToLower(strings.ReplaceAll(endpoint.Endpoint, " ", "-"))
+			file := filepath.Join(heatmapDir, framework+"_"+slug+".svg")
+			svg := heatmapSVG(fmt.Sprintf("%s – %s", framework, endpoint.Endpoint), endpoint.Heatmap)
+			if err := ioutil.WriteFile(file, []byte(svg), 0644); err != nil {
+				return nil, err
+			}
+
+			heatmaps[heatmapKey(framework, endpoint.Endpoint)] = relativeLink(readmeDir, file)
+		}
+	}
+
+	return heatmaps, nil
+}
+
+// heatmapSVG draws time on the x axis, latency bands on the y axis (slowest
+// on top) and the request count of each cell as color. Counts are
+// log-scaled so that rare slow requests stay visible next to the bulk.
+func heatmapSVG(title string, h *Heatmap) string {
+	lowest, highest, peak := len(h.BoundsUs), -1, int64(0)
+	for _, second := range h.Counts {
+		for band, count := range second {
+			if count == 0 {
+				continue
+			}
+			if band < lowest {
+				lowest = band
+			}
+			if band > highest {
+				highest = band
+			}
+			if count > peak {
+				peak = count
+			}
+		}
+	}
+	if highest < 0 {
+		lowest, highest = 0, 0
+	}
+
+	const left, top, bottom, right, cellHeight = 80, 36, 34, 16, 16
+	cellWidth := 600 / len(h.Counts)
+	if cellWidth > 24 {
+		cellWidth = 24
+	}
+	if cellWidth < 2 {
+		cellWidth = 2
+	}
+	rows := highest - lowest + 1
+	// Leave room for the title on short runs
+	width := left + len(h.Counts)*cellWidth + right
+	if width < 400 {
+		width = 400
+	}
+	height := top + rows*cellHeight + bottom
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" font-family="monospace" font-size="11">`+"\n", width, height)
+	fmt.Fprintf(&b, `<rect width="%d" height="%d" fill="#ffffff"/>`+"\n", width, height)
+	fmt.Fprintf(&b, `<text x="%d" y="20" font-size="13" font-weight="bold">%s</text>`+"\n", left, html.EscapeString(title))
+
+	for band := highest; band >= lowest; band-- {
+		y := top + (highest-band)*cellHeight
+		label := "<" + formatMicros(float64(h.BoundsUs[band]))
+		if band == len(h.BoundsUs)-1 && band > 0 {
+			label = "≥" + formatMicros(float64(h.BoundsUs[band-1]))
+		}
+		label = html.EscapeString(label)
+		fmt.Fprintf(&b, `<text x="%d" y="%d" text-anchor="end">%s</text>`+"\n", left-6, y+cellHeight-4, label)
+
+		for s, second := range h.Counts {
+			count := second[band]
+			fmt.Fprintf(&b, `<rect x="%d" y="%d" width="%d" height="%d" fill="%s"><title>%ds, %s: %d requests</title></rect>`+"\n",
+				left+s*cellWidth, y, cellWidth, cellHeight, heatmapColor(count, peak), s+1, label, count)
+		}
+	}
+
+	axisY := top + rows*cellHeight
+	step := (len(h.Counts) + 9) / 10
+	for s := 0; s < len(h.Counts); s += step {
+		fmt.Fprintf(&b, `<text x="%d" y="%d" text-anchor="middle">%d</text>`+"\n", left+s*cellWidth+cellWidth/2, axisY+14, s+1)
+	}
+	fmt.Fprintf(&b, `<text x="%d" y="%d" text-anchor="middle">seconds</text>`+"\n", left+len(h.Counts)*cellWidth/2, axisY+28)
+	b.WriteString("</svg>\n")
+
+	return b.String()
+}
+
+// heatmapColor interpolates from a pale yellow to a dark red.
+func heatmapColor(count, peak int64) string {
+	if count == 0 || peak == 0 {
+		return "#f4f4f4"
+	}
+	level := math.Log1p(float64(count)) / math.Log1p(float64(peak))
+	from, to := [3]float64{255, 237, 160}, [3]float64{128, 0, 38}
+	var rgb [3]int
+	for i := range rgb {
+		rgb[i] = int(from[i] + (to[i]-from[i])*level + 0.5)
+	}
+	return fmt.Sprintf("#%02x%02x%02x", rgb[0], rgb[1], rgb[2])
+}
+
+func createHeatmapSection(results map[string][]EndpointResult, heatmaps map[string]string) string {
+	endpointsToCompare := []string{"Root endpoint", "Health check", "User endpoint", "POST users"}
+	section := ""
+
+	for _, endpointName := range endpointsToCompare {
+		var endpointData []FrameworkData
+		for framework, endpoints := range results {
+			for _, endpoint := range endpoints {
+				if endpoint.Endpoint == endpointName && heatmaps[heatmapKey(framework, endpointName)] != "" {
+					endpointData = append(endpointData, FrameworkData{
+						Name: framework,
+						RPS:  parseRPS(endpoint.RequestsPerSec),
+						Data: endpoint,
+					})
+					break
+				}
+			}
+		}
+
+		if len(endpointData) == 0 {
+			continue
+		}
+
+		sort.Slice(endpointData, func(i, j int) bool {
+			return endpointData[i].RPS > endpointData[j].RPS
+		})
+
+		section += fmt.Sprintf("\n### %s\n\n", endpointName)
+		for _, fw := range endpointData {
+			section += fmt.Sprintf("![%s latency heatmap](%s)\n", fw.Name, heatmaps[heatmapKey(fw.Name, endpointName)])
+		}
+	}
+
+	if section == "" {
+		return ""
+	}
+
+	return "\n## 🌡️ Latency Heatmaps\n\n" +
+		"Requests per second and latency band, darker meaning more requests (log scale). " +
+		"Bimodal latency, such as GC pauses or a blocked event loop, shows up as a second band that the fixed percentiles hide.\n" + section
+}
+
+func createPGOSection(results *BenchmarkResults) string {
+	var variants []string
+	for name, info := range results.FrameworkInfo {
+		if info.Variant == "pgo" && len(results.Results[info.Base]) > 0 && len(results.Results[name]) > 0 {
+			variants = append(variants, name)
+		}
+	}
+
+	if len(variants) == 0 {
+		return ""
+	}
+	sort.Strings(variants)
+
+	section := "\n## 🚀 Profile-Guided Optimization\n\n" +
+		"Each Go server benchmarked as built, then rebuilt with `go build -pgo` from a CPU profile of the same workload and benchmarked again.\n\n" +
+		"| Framework | Endpoint | Requests/sec | With PGO | Change | P99 | P99 with PGO | Change |\n" +
+		"|-----------|----------|--------------|----------|--------|-----|--------------|--------|\n"
+
+	for _, variant := range variants {
+		base := results.FrameworkInfo[variant].Base
+		name := strings.Title(strings.ReplaceAll(base, "-", " "))
+		for _, before := range results.Results[base] {
+			for _, after := range results.Results[variant] {
+				if after.Endpoint != before.Endpoint || before.SlowClient != nil {
+					continue
+				}
+				section += fmt.Sprintf("| **%s** | %s | %s | %s | %s | %s | %s | %s |\n",
+					name,
+					before.Endpoint,
+					formatNumber(before.RequestsPerSec),
+					formatNumber(after.RequestsPerSec),
+					percentChange(parseRPS(before.RequestsPerSec), parseRPS(after.RequestsPerSec)),
+					orDash(before.LatencyPercentiles.P99),
+					orDash(after.LatencyPercentiles.P99),
+					percentChange(parseLatency(before.LatencyPercentiles.P99), parseLatency(after.LatencyPercentiles.P99)),
+				)
+			}
+		}
+	}
+
+	return section
+}
+
+func createScalingSection(results *BenchmarkResults) string {
+	sweeps := make(map[string][]string)
+	for name, info := range results.FrameworkInfo {
+		if info.Variant == "cpus" && info.CPUs > 0 && len(results.Results[name]) > 0 {
+			sweeps[info.Base] = append(sweeps[info.Base], name)
+		}
+	}
+
+	if len(sweeps) == 0 {
+		return ""
+	}
+
+	var bases []string
+	for base := range sweeps {
+		bases = append(bases, base)
+	}
+	sort.Strings(bases)
+
+	section := "\n## 📐 CPU Scaling\n\n" +
+		"`/` with each server pinned to a fixed number of CPUs (Go servers get a matching `GOMAXPROCS`). " +
+		"Scaling efficiency is throughput per core relative to the smallest CPU count; 100% means linear scaling.\n"
+
+	for _, base := range bases {
+		variants := sweeps[base]
+		sort.Slice(variants, func(i, j int) bool {
+			return results.FrameworkInfo[variants[i]].CPUs < results.FrameworkInfo[variants[j]].CPUs
+		})
+
+		section += fmt.Sprintf("\n### %s\n\n", strings.Title(strings.ReplaceAll(base, "-", " ")))
+		section += "| CPUs | Requests/sec | Per Core | Scaling Efficiency | P99 |\n"
+		section += "|------|--------------|----------|--------------------|-----|\n"
+
+		baselinePerCore := 0.0
+		for _, variant := range variants {
+			cpus := results.FrameworkInfo[variant].CPUs
+			for _, endpoint := range results.Results[variant] {
+				if endpoint.Endpoint != "Root endpoint" {
+					continue
+				}
+
+				perCore := parseRPS(endpoint.RequestsPerSec) / float64(cpus)
+				if baselinePerCore == 0 {
+					baselinePerCore = perCore
+				}
+				efficiency := "-"
+				if baselinePerCore > 0 {
+					efficiency = fmt.Sprintf("%.0f%%", perCore/baselinePerCore*100)
+				}
+
+				section += fmt.Sprintf("| %d | %s | %s | %s | %s |\n",
+					cpus,
+					formatNumber(endpoint.RequestsPerSec),
+					formatNumber(fmt.Sprintf("%.0f", perCore)),
+					efficiency,
+					orDash(endpoint.LatencyPercentiles.P99),
+				)
+				break
+			}
+		}
+	}
+
+	return section
+}
+
+// profileKinds are the pprof profiles captured per endpoint, in table order.
+var profileKinds = []struct {
+	Kind  string
+	Label string
+}{
+	{"cpu", "CPU"},
+	{"heap", "Heap"},
+	{"allocs", "Allocs"},
+	{"mutex", "Mutex"},
+	{"block", "Block"},
+}
+
+// gcSettingOrder sorts GOGC and GOMEMLIMIT values: "off" first for
+// GOMEMLIMIT (no limit), last for GOGC (no collection), numbers ascending.
+func gcSettingOrder(value string, offFirst bool) float64 {
+	if value == "off" || value == "" {
+		if offFirst {
+			return math.Inf(-1)
+		}
+		return math.Inf(1)
+	}
+	n, unit := 0.0, ""
+	fmt.Sscanf(value, "%g%s", &n, &unit)
+	scale := map[string]float64{"KiB": 1 << 10, "MiB": 1 << 20, "GiB": 1 << 30, "TiB": 1 << 40}[unit]
+	if scale == 0 {
+		scale = 1
+	}
+	return n * scale
+}
+
+func createGCMatrixSection(results *BenchmarkResults) string {
+	matrices := make(map[string][]string)
+	for name, info := range results.FrameworkInfo {
+		if info.Variant == "gc" && len(results.Results[name]) > 0 {
+			matrices[info.Base] = append(matrices[info.Base], name)
+		}
+	}
+
+	if len(matrices) == 0 {
+		return ""
+	}
+
+	var bases []string
+	for base := range matrices {
+		bases = append(bases, base)
+	}
+	sort.Strings(bases)
+
+	section := "\n## 🗑️ GOGC / GOMEMLIMIT Matrix\n\n" +
+		"`/` with each Go server run under every combination of `GOGC` and `GOMEMLIMIT`. " +
+		"Throughput is relative to the server's main run with Go's defaults (`GOGC=100`, no memory limit); peak RSS is the largest resident memory sampled across all endpoint runs of that setting.\n"
+
+	for _, base := range bases {
+		variants := matrices[base]
+		sort.Slice(variants, func(i, j int) bool {
+			a, b := results.FrameworkInfo[variants[i]], results.FrameworkInfo[variants[j]]
+			if a.GOMemLimit != b.GOMemLimit {
+				return gcSettingOrder(a.GOMemLimit, true) < gcSettingOrder(b.GOMemLimit, true)
+			}
+			return gcSettingOrder(a.GOGC, false) < gcSettingOrder(b.GOGC, false)
+		})
+
+		rootEndpoint := func(name string) *EndpointResult {
+			for i, endpoint := range results.Results[name] {
+				if endpoint.Endpoint == "Root endpoint" {
+					return &results.Results[name][i]
+				}
+			}
+			return nil
+		}
+
+		// The base framework's own run uses Go's defaults
+		defaultRPS := 0.0
+		if endpoint := rootEndpoint(base); endpoint != nil {
+			defaultRPS = parseRPS(endpoint.RequestsPerSec)
+		}
+
+		section += fmt.Sprintf("\n### %s\n\n", strings.Title(strings.ReplaceAll(base, "-", " ")))
+		section += "| GOGC | GOMEMLIMIT | Requests/sec | vs Default | P99 | Peak RSS |\n"
+		section += "|------|------------|--------------|------------|-----|----------|\n"
+
+		for _, variant := range variants {
+			info := results.FrameworkInfo[variant]
+			endpoint := rootEndpoint(variant)
+			if endpoint == nil {
+				continue
+			}
+
+			peakRSS := "-"
+			if info.Memory != nil && len(info.Memory.Samples) > 0 {
+				var peak int64
+				for _, sample := range info.Memory.Samples {
+					peak = max(peak, sample[1])
+				}
+				peakRSS = formatMB(peak)
+			}
+
+			section += fmt.Sprintf("| %s | %s | %s | %s | %s | %s |\n",
+				info.GOGC,
+				orDash(info.GOMemLimit),
+				formatNumber(endpoint.RequestsPerSec),
+				percentChange(defaultRPS, parseRPS(endpoint.RequestsPerSec)),
+				orDash(endpoint.LatencyPercentiles.P99),
+				peakRSS,
+			)
+		}
+	}
+
+	return section
+}
+
+func createProfileSection(results map[string][]EndpointResult, readmeDir string) string {
+	var frameworks []string
+	for framework, endpoints := range results {
+		for _, endpoint := range endpoints {
+			if len(endpoint.Profiles) > 0 {
+				frameworks = append(frameworks, framework)
+				break
+			}
+		}
+	}
+
+	if len(frameworks) == 0 {
+		return ""
+	}
+	sort.Strings(frameworks)
+
+	section := "\n## 🔬 Go Profiles\n\n" +
+		"pprof profiles captured while each endpoint was under load. Each cell links the top functions of the profile " +
+		"and the raw profile for `go tool pprof`.\n"
+
+	for _, framework := range frameworks {
+		section += fmt.Sprintf("\n### %s\n\n", strings.Title(strings.ReplaceAll(framework, "-", " ")))
+		section += "| Endpoint |"
+		separator := "|----------|"
+		for _, k := range profileKinds {
+			section += fmt.Sprintf(" %s |", k.Label)
+			separator += "-----|"
+		}
+		section += "\n" + separator + "\n"
+
+		for _, endpoint := range results[framework] {
+			if len(endpoint.Profiles) == 0 {
+				continue
+			}
+			section += fmt.Sprintf("| %s |", endpoint.Endpoint)
+			for _, k := range profileKinds {
+				p, ok := endpoint.Profiles[k.Kind]
+				if !ok {
+					section += " - |"
+					continue
+				}
+				section += fmt.Sprintf(" [top](%s) · [pprof](%s) |", relativeLink(readmeDir, p.Summary), relativeLink(readmeDir, p.File))
+			}
+			section += "\n"
+		}
+	}
+
+	return section
+}
+
+func createResourceSection(results *BenchmarkResults) string {
+	var entries []string
+	for name, info := range results.FrameworkInfo {
+		if info.ResourceLimits != nil {
+			entries = append(entries, name)
+		}
+	}
+
+	if len(entries) == 0 {
+		return ""
+	}
+	sort.Strings(entries)
+
+	section := "\n## 📦 Resource Limits\n\n" +
+		"Servers ran inside a cgroup v2 with `cpu.max` and `memory.max` set. Throttled periods are the share of 100ms CPU periods " +
+		"in which the server used up its quota and was paused; OOM kills mean the server exceeded its memory limit.\n\n" +
+		"| Framework | CPU Limit | Memory Limit | Throttled Periods | Throttled Time | Peak Memory | OOM Kills |\n" +
+		"|-----------|-----------|--------------|-------------------|----------------|-------------|-----------|\n"
+
+	for _, name := range entries {
+		limits := results.FrameworkInfo[name].ResourceLimits
+		throttled := "-"
+		if limits.NrPeriods > 0 {
+			throttled = fmt.Sprintf("%.1f%% (%d of %d)", float64(limits.NrThrottled)/float64(limits.NrPeriods)*100, limits.NrThrottled, limits.NrPeriods)
+		}
+		peak := "-"
+		if limits.MemoryPeakBytes > 0 {
+			peak = formatMB(limits.MemoryPeakBytes)
+		}
+		oomKills := fmt.Sprintf("%d", limits.OOMKill)
+		if limits.OOMKill > 0 {
+			oomKills = fmt.Sprintf("⚠️ %d", limits.OOMKill)
+		}
+
+		section += fmt.Sprintf("| **%s** | %s | %s | %s | %s | %s | %s |\n",
+			strings.Title(strings.ReplaceAll(name, "-", " ")),
+			limits.CPUs,
+			limits.Memory,
+			throttled,
+			formatMicros(float64(limits.ThrottledUsec)),
+			peak,
+			oomKills,
+		)
+	}
+
+	return section
+}
+
+// warmupTicks is the number of points the warm-up curve is folded into.
+const warmupTicks = 20
+
+func createWarmupSection(results *BenchmarkResults) string {
+	var frameworks []string
+	for name, info := range results.FrameworkInfo {
+		if info.Warmup != nil && !matrixVariants[info.Variant] {
+			frameworks = append(frameworks, name)
+		}
+	}
+
+	if len(frameworks) == 0 {
+		return ""
+	}
+	sort.Strings(frameworks)
+
+	first := results.FrameworkInfo[frameworks[0]].Warmup
+	section := "\n## 🔥 Warm-Up\n\n" +
+		fmt.Sprintf("Each server was loaded on `/` until requests/sec and P99 over the last %d seconds varied by at most %.0f%% and %.0f%% (coefficient of variation), ",
+			first.WindowSeconds, first.MaxRPSCV*100, first.MaxP99CV*100) +
+		"so JIT compilers, heaps and caches settle before measuring.\n\n" +
+		"| Framework | Warm-Up | Requests/sec | Requests/sec CV | P99 | P99 CV | Verdict |\n" +
+		"|-----------|---------|--------------|-----------------|-----|--------|---------|\n"
+
+	for _, name := range frameworks {
+		w := results.FrameworkInfo[name].Warmup
+		verdict := "✅ Stable"
+		if !w.Stable {
+			verdict = "⚠️ Not stable at the cap"
+		}
+		section += fmt.Sprintf("| **%s** | %.1fs | %s | %.1f%% | %s | %.1f%% | %s |\n",
+			strings.Title(strings.ReplaceAll(name, "-", " ")),
+			w.Seconds,
+			formatNumber(fmt.Sprintf("%.0f", w.RequestsPerSec)),
+			w.RPSCV*100,
+			formatMicros(w.P99Us),
+			w.P99CV*100,
+			verdict,
+		)
+	}
+
+	return section
+}
+
+// createIterationSection compares the repeated runs of each framework
+// (--iterations) on `/`, to show how much of a difference between frameworks
+// is run-to-run noise.
+func createIterationSection(results *BenchmarkResults) string {
+	runs := make(map[string][]string)
+	for name, info := range results.FrameworkInfo {
+		if info.Variant == "iteration" && len(results.Results[name]) > 0 && len(results.Results[info.Base]) > 0 {
+			runs[info.Base] = append(runs[info.Base], name)
+		}
+	}
+
+	if len(runs) == 0 {
+		return ""
+	}
+
+	var bases []string
+	for base := range runs {
+		bases = append(bases, base)
+	}
+	sort.Strings(bases)
+
+	rootRPS := func(name string) float64 {
+		for _, endpoint := range results.Results[name] {
+			if endpoint.Endpoint == "Root endpoint" {
+				return parseRPS(endpoint.RequestsPerSec)
+			}
+		}
+		return 0
+	}
+
+	section := "\n## 🔁 Run-to-Run Variation\n\n" +
+		"Requests/sec on `/` of every iteration of each framework, in run order. " +
+		"The coefficient of variation (CV) is the spread between iterations; differences between frameworks smaller than it are noise.\n\n" +
+		"| Framework | Requests/sec per Iteration | Mean | CV |\n" +
+		"|-----------|----------------------------|------|----|\n"
+
+	for _, base := range bases {
+		names := runs[base]
+		sort.Slice(names, func(i, j int) bool {
+			return results.FrameworkInfo[names[i]].Iteration < results.FrameworkInfo[names[j]].Iteration
+		})
+		names = append([]string{base}, names...)
+
+		var values []string
+		var mean float64
+		for _, name := range names {
+			rps := rootRPS(name)
+			mean += rps
+			values = append(values, formatNumber(fmt.Sprintf("%.0f", rps)))
+		}
+		mean /= float64(len(names))
+
+		cv := "-"
+		if mean > 0 {
+			var squares float64
+			for _, name := range names {
+				d := rootRPS(name) - mean
+				squares += d * d
+			}
+			cv = fmt.Sprintf("%.1f%%", math.Sqrt(squares/float64(len(names)))/mean*100)
+		}
+
+		section += fmt.Sprintf("| **%s** | %s | %s | %s |\n",
+			strings.Title(strings.ReplaceAll(base, "-", " ")),
+			strings.Join(values, " → "),
+			formatNumber(fmt.Sprintf("%.0f", mean)),
+			cv,
+		)
+	}
+
+	return section
+}
+
+func createColdStartSection(results *BenchmarkResults) string {
+	var frameworks []string
+	for name, info := range results.FrameworkInfo {
+		if info.ColdStart != nil && len(info.ColdStart.WarmupCurveUs) > 0 {
+			frameworks = append(frameworks, name)
+		}
+	}
+
+	if len(frameworks) == 0 {
+		return ""
+	}
+	sort.Strings(frameworks)
+
+	section := "\n## ❄️ Cold Start\n\n" +
+		"Time from spawning the server process to its first successful `/health` response (polled every millisecond), over several cold starts. " +
+		"The first request is the first `/` request to the fresh process, including its connection; the warm-up curve is the median latency of each of the first requests, in order.\n\n" +
+		"| Framework | Starts | Startup (median) | Startup (max) | First Request | Last Request | Warm-up Curve |\n" +
+		"|-----------|--------|------------------|---------------|---------------|--------------|---------------|\n"
+
+	for _, name := range frameworks {
+		cold := results.FrameworkInfo[name].ColdStart
+		curve := cold.WarmupCurveUs
+
+		// Fold the curve into warmupTicks means so it fits a table cell
+		size := (len(curve) + warmupTicks - 1) / warmupTicks
+		var points []float64
+		for start := 0; start < len(curve); start += size {
+			end := start + size
+			if end > len(curve) {
+				end = len(curve)
+			}
+			sum := 0.0
+			for _, us := range curve[start:end] {
+				sum += float64(us)
+			}
+			points = append(points, sum/float64(end-start))
+		}
+
+		section += fmt.Sprintf("| **%s** | %d | %.1fms | %.1fms | %s | %s | `%s` |\n",
+			strings.Title(strings.ReplaceAll(name, "-", " ")),
+			cold.Runs,
+			cold.StartupMedianMs,
+			cold.StartupMaxMs,
+			formatMicros(float64(cold.FirstRequestMedianUs)),
+			formatMicros(float64(curve[len(curve)-1])),
+			sparkline(points),
+		)
+	}
+
+	return section
+}
+
+func formatMB(bytes int64) string {
+	return fmt.Sprintf("%.1f MB", float64(bytes)/(1<<20))
+}
+
+func createFootprintSection(results *BenchmarkResults) string {
+	var goServers, bunServers []string
+	for name, info := range results.FrameworkInfo {
+		if info.Footprint == nil {
+			continue
+		}
+		switch info.Footprint.Runtime {
+		case "go":
+			goServers = append(goServers, name)
+		case "bun":
+			bunServers = append(bunServers, name)
+		}
+	}
+
+	if len(goServers) == 0 && len(bunServers) == 0 {
+		return ""
+	}
+	sort.Strings(goServers)
+	sort.Strings(bunServers)
+
+	section := "\n## 👣 Footprint\n\n" +
+		"What each server costs to build and ship, next to how fast it runs.\n"
+
+	if len(goServers) > 0 {
+		section += "\n### Go Servers\n\n" +
+			"Build time with the standard library packages the server uses already compiled, so it covers the framework, its modules and the server; " +
+			"the cold build starts from an empty build cache and is mostly the standard library. Modules are already downloaded for both. " +
+			"The stripped binary is built with `-ldflags=\"-s -w\"`.\n\n" +
+			"| Framework | Build Time | Cold Build | Binary | Stripped | Modules (direct) |\n" +
+			"|-----------|------------|------------|--------|----------|------------------|\n"
+		for _, name := range goServers {
+			f := results.FrameworkInfo[name].Footprint
+			buildTime := fmt.Sprintf("%.1fs", float64(f.BuildTimeMs)/1000)
+			coldBuild := fmt.Sprintf("%.1fs", float64(f.ColdBuildTimeMs)/1000)
+			if f.ColdBuildTimeMs == 0 {
+				// Recorded before the two were split: build_time_ms was
+				// the cold build
+				buildTime, coldBuild = "-", buildTime
+			}
+			section += fmt.Sprintf("| **%s** | %s | %s | %s | %s | %d (%d) |\n",
+				strings.Title(strings.ReplaceAll(name, "-", " ")),
+				buildTime,
+				coldBuild,
+				formatMB(f.BinaryBytes),
+				formatMB(f.StrippedBytes),
+				f.Modules,
+				f.DirectModules,
+			)
+		}
+	}
+
+	if len(bunServers) > 0 {
+		section += "\n### Bun Servers\n\n" +
+			"Install time of `bun install --force`; Bun runs the TypeScript sources directly, so there is no build step.\n\n" +
+			"| Framework | Install Time | node_modules | Packages (direct) |\n" +
+			"|-----------|--------------|--------------|-------------------|\n"
+		for _, name := range bunServers {
+			f := results.FrameworkInfo[name].Footprint
+			section += fmt.Sprintf("| **%s** | %.1fs | %s | %d (%d) |\n",
+				strings.Title(strings.ReplaceAll(name, "-", " ")),
+				float64(f.InstallTimeMs)/1000,
+				formatMB(f.NodeModulesBytes),
+				f.Packages,
+				f.DirectDependencies,
+			)
+		}
+	}
+
+	return section
+}
+
+// memoryChartWidth is the number of points a memory chart is folded into.
+const memoryChartWidth = 40
+
+// memoryChart draws one column of the memory samples as a sparkline of
+// memoryChartWidth means.
+func memoryChart(samples [][3]int64, column int) string {
+	size := (len(samples) + memoryChartWidth - 1) / memoryChartWidth
+	var points []float64
+	for start := 0; start < len(samples); start += size {
+		end := start + size
+		if end > len(samples) {
+			end = len(samples)
+		}
+		sum := 0.0
+		for _, sample := range samples[start:end] {
+			sum += float64(sample[column])
+		}
+		points = append(points, sum/float64(end-start))
+	}
+	return sparkline(points)
+}
+
+func createMemorySection(results *BenchmarkResults) string {
+	var frameworks []string
+	for name, info := range results.FrameworkInfo {
+		// GC matrix entries are sampled for their RSS, shown in the matrix
+		if info.Memory != nil && len(info.Memory.Samples) > 0 && info.Variant != "gc" {
+			frameworks = append(frameworks, name)
+		}
+	}
+
+	if len(frameworks) == 0 {
+		return ""
+	}
+	sort.Strings(frameworks)
+
+	threshold := results.FrameworkInfo[frameworks[0]].Memory.ThresholdMBPerMin
+	section := "\n## 🧠 Memory Over Time\n\n" +
+		"Resident memory of each server (and any process it started) and, for Go servers, heap in use from `runtime/metrics`, sampled through every endpoint run. " +
+		fmt.Sprintf("Growth is a linear fit after warm-up; sustained growth of %.1f MB/min or more is flagged as a suspected leak.\n\n", threshold) +
+		"| Framework | Duration | RSS | RSS Growth | Heap In Use | Heap Growth | Verdict |\n" +
+		"|-----------|----------|-----|------------|-------------|-------------|---------|\n"
+
+	chart := "\n```\n"
+	for _, name := range frameworks {
+		m := results.FrameworkInfo[name].Memory
+		first, last := m.Samples[0], m.Samples[len(m.Samples)-1]
+
+		heap, heapGrowth := "-", "-"
+		if m.HeapSampled {
+			heap = fmt.Sprintf("%s → %s", formatMB(first[2]), formatMB(last[2]))
+			heapGrowth = fmt.Sprintf("%+.2f MB/min (R² %.2f)", m.HeapSlopeMBPerMin, m.HeapRSquared)
+		}
+		verdict := "✅ Stable"
+		if m.SuspectedLeak {
+			verdict = "⚠️ Suspected leak"
+		}
+
+		displayName := strings.Title(strings.ReplaceAll(name, "-", " "))
+		section += fmt.Sprintf("| **%s** | %.0fs | %s → %s | %+.2f MB/min (R² %.2f) | %s | %s | %s |\n",
+			displayName,
+			float64(last[0])/1000,
+			formatMB(first[1]),
+			formatMB(last[1]),
+			m.RSSSlopeMBPerMin,
+			m.RSSRSquared,
+			heap,
+			heapGrowth,
+			verdict,
+		)
+
+		chart += fmt.Sprintf("%-20s RSS  %s\n", displayName, memoryChart(m.Samples, 1))
+		if m.HeapSampled {
+			chart += fmt.Sprintf("%-20s heap %s\n", "", memoryChart(m.Samples, 2))
+		}
+	}
+
+	return section + chart + "```\n"
+}
+
+func resourceNotice(profile string) string {
+	if profile == "" {
+		return ""
+	}
+	return fmt.Sprintf("\n> 📦 Servers ran under the CPU and memory limits of the `%s` resource profile from benchmark.json.\n", profile)
+}
+
+// warmupTime describes the warm-up; runs recorded before adaptive warm-up
+// existed had a fixed one.
+func warmupTime(config BenchmarkConfig) string {
+	if config.WarmupMax == 0 {
+		return fmt.Sprintf("%d seconds", config.WarmupTime)
+	}
+	return fmt.Sprintf("until throughput and P99 are stable, %d–%d seconds", config.WarmupTime, config.WarmupMax)
+}
+
+// runOrder describes the order the frameworks ran in; runs recorded before
+// the order was recorded ran once each, in configuration order.
+func runOrder(order *RunOrder) string {
+	if order == nil {
+		return "Configuration order"
+	}
+
+	description := "Configuration order"
+	if order.Shuffled {
+		description = fmt.Sprintf("Shuffled (seed %d, reproduce with `--seed %d`)", order.Seed, order.Seed)
+	}
+	if order.Iterations > 1 {
+		style := "back to back"
+		if order.Interleaved {
+			style = "interleaved"
+		}
+		description += fmt.Sprintf(", %d iterations %s", order.Iterations, style)
+	}
+	if order.CooldownSeconds > 0 {
+		description += fmt.Sprintf(", %ds cool-down between servers", order.CooldownSeconds)
+	}
+	return description
+}
+
+func hostNotice(host *HostInfo) string {
+	if host == nil || len(host.Warnings) == 0 {
+		return ""
+	}
+	notice := "\n> 🌡️ The pre-flight checks found a noisy host, so these numbers may be skewed:\n"
+	for _, warning := range host.Warnings {
+		notice += "> - " + warning + "\n"
+	}
+	return notice
+}
+
+// hostEnvironment lists the machine a run was made on; runs recorded before
+// host fingerprints existed only get the generic description.
+func hostEnvironment(config BenchmarkConfig) string {
+	host := config.Host
+	if host == nil {
+		return "- **OS**: macOS/Linux\n" +
+			"- **CPU**: Multi-core (threads configurable)\n" +
+			"- **Memory**: Sufficient RAM allocated per server\n" +
+			"- **Network**: " + networkDescription(config.NetworkProfile) + "\n"
+	}
+
+	optional := func(value string) string {
+		if value == "" {
+			return "not installed"
+		}
+		return value
+	}
+	load, memory, somaxconn, openFiles := "-", "-", "-", "-"
+	if host.LoadAverage != nil {
+		load = fmt.Sprintf("%.2f", *host.LoadAverage)
+	}
+	if host.MemoryAvailableMB != nil {
+		memory = fmt.Sprintf("%d MB available", *host.MemoryAvailableMB)
+	}
+	if host.Somaxconn != nil {
+		somaxconn = strconv.Itoa(*host.Somaxconn)
+	}
+	if host.OpenFilesLimit != nil {
+		openFiles = strconv.Itoa(*host.OpenFilesLimit)
+	}
+
+	return fmt.Sprintf("- **OS**: %s %s\n", host.OS, host.Kernel) +
+		fmt.Sprintf("- **CPU**: %s, %d cores (governor: %s)\n", host.CPUModel, host.CPUCores, host.CPUGovernor) +
+		fmt.Sprintf("- **Memory**: %s\n", memory) +
+		fmt.Sprintf("- **Load Average**: %s at start\n", load) +
+		fmt.Sprintf("- **Limits**: somaxconn %s, open files %s\n", somaxconn, openFiles) +
+		fmt.Sprintf("- **Toolchains**: Go %s, Bun %s\n", optional(strings.TrimPrefix(host.GoVersion, "go")), optional(host.BunVersion)) +
+		"- **Network**: " + networkDescription(config.NetworkProfile) + "\n"
+}
+
+func hostChecked(host *HostInfo) string {
+	if host == nil {
+		return ""
+	}
+	return ", fingerprinted and checked for background load before the run (see Environment)"
+}
+
+func profilingNotice(profiling bool) string {
+	if !profiling {
+		return ""
+	}
+	return "\n> 🔬 Go servers were profiled during these runs (pprof with mutex and block sampling), which costs some throughput.\n"
+}
+
+func cpuIsolation(config BenchmarkConfig) string {
+	if !config.CPUIsolation {
+		return "Off (load generator and servers share all CPUs)"
+	}
+	return fmt.Sprintf("Load generator on CPUs %s, servers on CPUs %s", config.LoadGenCPUs, config.ServerCPUs)
+}
+
+func pipelineNotice(depth int) string {
+	if depth <= 1 {
+		return ""
+	}
+	return fmt.Sprintf("\n> ⚠️ These results were measured with HTTP/1.1 pipelining (%d requests in flight per connection) and are not comparable with non-pipelined runs.\n", depth)
+}
+
+// networkProfile names the emulated network a run used; runs recorded before
+// network profiles existed were all made over plain loopback.
+func networkProfile(profile string) string {
+	if profile == "" {
+		return "loopback"
+	}
+	return profile
+}
+
+func networkDescription(profile string) string {
+	if networkProfile(profile) == "loopback" {
+		return "Local loopback (eliminates network latency)"
+	}
+	return fmt.Sprintf("Emulated `%s` profile (latency, jitter, bandwidth cap and resets injected by scripts/faultproxy)", profile)
+}
+
+func networkNotice(profile string) string {
+	if networkProfile(profile) == "loopback" {
+		return ""
+	}
+	return fmt.Sprintf("\n> 🌐 These results were measured through the fault-injecting proxy with the `%s` network profile from benchmark.json.\n", profile)
+}
+
+func generateREADME(results *BenchmarkResults, heatmaps map[string]string, readmeDir string) string {
+	if results == nil || len(results.Results) == 0 {
+		return "# Benchmark Results\n\nNo benchmark data available. Run `./scripts/benchmark.sh` to generate results."
+	}
+
+	loadGenerator := "[wrk](https://github.com/wg/wrk)"
+	if results.Configuration.LoadGenerator == "go" {
+		loadGenerator = "loadgen (`scripts/loadgen`, Go)"
+	}
+
+	markLoadgenBound(results)
+	headline := headlineResults(results)
+
+	depth := pipelineDepth(results.Configuration.PipelineDepth)
+	pipelining := "Disabled (one request in flight per connection)"
+	if depth > 1 {
+		pipelining = fmt.Sprintf("HTTP/1.1 pipelining, %d requests in flight per connection", depth)
+	}
+
+	readme := fmt.Sprintf(`# JS vs Go Web Framework Benchmark
+
+A comprehensive performance comparison between JavaScript (Bun) and Go web frameworks.
+
+## 🚀 Quick Results
+%s
+
+%s
+
+## 📊 Performance Chart
+
+%s
+
+## 🔧 Frameworks Tested
+
+### Go Frameworks
+- **Go Vanilla (net/http)**: Standard Go HTTP server using the built-in `+"`net/http`"+` package
+- **Go Vanilla Optimized (net/http)**: The same server with zero-allocation handlers: pooled buffers, hand-written JSON and shared headers
+- **Go Vanilla Mux122 (net/http)**: The same server routed with Go 1.22 `+"`ServeMux`"+` patterns (`+"`GET /user/{id}`"+`, `+"`r.PathValue`"+`)
+- **Go Fiber**: Fast Express-inspired web framework built on top of Fasthttp
+
+### JavaScript/TypeScript Frameworks (Bun Runtime)
+- **Bun Vanilla**: Pure Bun HTTP server using Bun's native HTTP APIs
+- **Hono.js**: Ultrafast web framework for Cloudflare Workers, Deno, Bun, and Node.js
+
+### Baseline
+- **Raw TCP**: Precomputed responses written straight to the socket with Go's `+"`net`"+` package, without an HTTP parser; the upper bound for this machine
+
+## 📈 Detailed Results by Endpoint
+
+%s
+%s
+## ⚙️ Benchmark Configuration
+
+- **Duration**: %d seconds
+- **Connections**: %d
+- **Threads**: %d
+- **Warmup Time**: %s
+- **Run Order**: %s
+- **Pipelining**: %s
+- **Network Profile**: %s
+- **CPU Isolation**: %s
+- **Tool**: %s
+- **Last Updated**: %s
+
+## 🛠️ Setup & Running
+
+### Prerequisites
+
+`+"```bash"+`
+# Install Go
+brew install go
+
+# Install Bun
+curl -fsSL https://bun.sh/install | bash
+
+# Install wrk (macOS)
 brew install wrk
 
 # Install wrk (Ubuntu/Debian)
@@ -381,19 +2443,26 @@ Each server implements the following endpoints:
 Based on the latest benchmark results:
 
 `,
-		createPerformanceTable(results.Results),
-		createASCIIChart(results.Results),
-		createEndpointComparison(results.Results),
+		pipelineNotice(depth)+networkNotice(results.Configuration.NetworkProfile)+resourceNotice(results.Configuration.ResourceProfile)+profilingNotice(results.Configuration.Profiling)+hostNotice(results.Configuration.Host)+calibrationNotice(results),
+		createPerformanceTable(headline),
+		createASCIIChart(headline),
+		createEndpointComparison(headline),
+		createBaselineSection(results, headline)+createHandlerGapSection(headline)+createRoutingSection(headline)+createCalibrationSection(results)+createPGOSection(results)+createScalingSection(results)+createGCMatrixSection(results)+createResourceSection(results)+createWarmupSection(results)+createIterationSection(results)+createColdStartSection(results)+createFootprintSection(results)+createMemorySection(results)+createTimeseriesSection(headline)+createGCSection(headline)+createHeatmapSection(headline, heatmaps)+createTimingBreakdown(headline)+createSlowClientSection(headline)+createShutdownSection(headline)+createProfileSection(headline, readmeDir),
 		results.Configuration.Duration,
 		results.Configuration.Connections,
 		results.Configuration.Threads,
-		results.Configuration.WarmupTime,
+		warmupTime(results.Configuration),
+		runOrder(results.RunOrder),
+		pipelining,
+		networkProfile(results.Configuration.NetworkProfile),
+		cpuIsolation(results.Configuration),
+		loadGenerator,
 		results.Timestamp,
 	)
 
 	// Add key findings based on results
 	var frameworks []FrameworkData
-	for framework, endpoints := range results.Results {
+	for framework, endpoints := range headline {
 		for _, endpoint := range endpoints {
 			if endpoint.Endpoint == "Root endpoint" && endpoint.RequestsPerSec != "" {
 				rps := parseRPS(endpoint.RequestsPerSec)
@@ -441,17 +2510,13 @@ This benchmark runs automatically:
 ## 📚 Technical Notes
 
 ### Methodology
-- Each server runs on the same hardware configuration
+- Each server runs on the same hardware configuration` + hostChecked(results.Configuration.Host) + `
 - Servers are warmed up before benchmarking begins
 - Multiple endpoints tested to simulate real-world usage
 - Latency percentiles captured for detailed analysis
 
 ### Environment
-- **OS**: macOS/Linux
-- **CPU**: Multi-core (threads configurable)
-- **Memory**: Sufficient RAM allocated per server
-- **Network**: Local loopback (eliminates network latency)
-
+` + hostEnvironment(results.Configuration) + `
 ## 🤝 Contributing
 
 Feel free to:
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func seconds(n int, bucket func(i int) SecondBucket) []SecondBucket {
+	series := make([]SecondBucket, n)
+	for i := range series {
+		series[i] = bucket(i)
+		series[i].Second = i + 1
+	}
+	return series
+}
+
+func TestFoldSeconds(t *testing.T) {
+	short := seconds(10, func(i int) SecondBucket {
+		return SecondBucket{Requests: int64(100 * i), P99: int64(i), Errors: int64(i % 2)}
+	})
+	rps, p99, errors := foldSeconds(short)
+	if len(rps) != 10 || len(p99) != 10 || len(errors) != 10 {
+		t.Fatalf("10 seconds folded into %d/%d/%d columns, want 10", len(rps), len(p99), len(errors))
+	}
+	for i := range short {
+		if rps[i] != float64(100*i) || p99[i] != float64(i) || errors[i] != float64(i%2) {
+			t.Errorf("column %d = (%v, %v, %v), want the second unchanged", i, rps[i], p99[i], errors[i])
+		}
+	}
+
+	// 121 seconds fold three to a column: throughput is averaged, P99 and
+	// errors keep their worst second, and the last column holds the rest
+	long := seconds(121, func(i int) SecondBucket {
+		b := SecondBucket{Requests: 900, P99: 100}
+		if i%3 == 1 {
+			b.Requests, b.P99, b.Errors = 0, 5000, 7
+		}
+		return b
+	})
+	rps, p99, errors = foldSeconds(long)
+	if len(rps) != 41 {
+		t.Fatalf("121 seconds folded into %d columns, want 41", len(rps))
+	}
+	if rps[0] != 600 || p99[0] != 5000 || errors[0] != 7 {
+		t.Errorf("first column = (%v, %v, %v), want (600, 5000, 7)", rps[0], p99[0], errors[0])
+	}
+	if last := len(rps) - 1; rps[last] != 900 || p99[last] != 100 || errors[last] != 0 {
+		t.Errorf("last column = (%v, %v, %v), want (900, 100, 0)", rps[last], p99[last], errors[last])
+	}
+
+	if rps, _, _ := foldSeconds(nil); len(rps) != 0 {
+		t.Errorf("no seconds folded into %d columns", len(rps))
+	}
+}
+
+func TestGCLatencyCorrelation(t *testing.T) {
+	p99s := func(values ...int64) []SecondBucket {
+		return seconds(len(values), func(i int) SecondBucket { return SecondBucket{P99: values[i]} })
+	}
+	gcs := func(pauses ...float64) []GCSecond {
+		gc := make([]GCSecond, len(pauses))
+		for i, p := range pauses {
+			gc[i] = GCSecond{Second: i + 1, PauseUs: p}
+			if p > 0 {
+				gc[i].GCCycles = 1
+			}
+		}
+		return gc
+	}
+
+	tests := []struct {
+		name         string
+		series       []SecondBucket
+		gc           []GCSecond
+		r            float64
+		spikesWithGC int
+		spikes       int
+	}{
+		{
+			name:   "pauses track P99",
+			series: p99s(100, 200, 300, 400),
+			gc:     gcs(10, 20, 30, 40),
+			r:      1,
+		},
+		{
+			name:   "pauses against P99",
+			series: p99s(100, 200, 300, 400),
+			gc:     gcs(40, 30, 20, 10),
+			r:      -1,
+		},
+		{
+			name:   "flat P99",
+			series: p99s(100, 100, 100, 100),
+			gc:     gcs(10, 20, 30, 40),
+			r:      math.NaN(),
+		},
+		{
+			name:   "too few seconds",
+			series: p99s(100, 200),
+			gc:     gcs(10, 20),
+			r:      math.NaN(),
+		},
+		{
+			// Seconds above twice the median P99 are spikes; one of the
+			// two has a GC cycle
+			name:         "spikes",
+			series:       p99s(100, 100, 100, 100, 100, 500, 600),
+			gc:           gcs(0, 0, 0, 0, 0, 50, 0),
+			r:            0.5404361177891456,
+			spikesWithGC: 1,
+			spikes:       2,
+		},
+		{
+			// Seconds without GC data are left out
+			name:   "missing GC seconds",
+			series: p99s(100, 100, 100, 100, 900),
+			gc:     gcs(10, 20, 30),
+			r:      math.NaN(),
+		},
+	}
+	for _, tt := range tests {
+		r, spikesWithGC, spikes := gcLatencyCorrelation(tt.series, tt.gc)
+		sameR := math.IsNaN(r) && math.IsNaN(tt.r) || math.Abs(r-tt.r) < 1e-9
+		if !sameR || spikesWithGC != tt.spikesWithGC || spikes != tt.spikes {
+			t.Errorf("%s: gcLatencyCorrelation = (%v, %d, %d), want (%v, %d, %d)",
+				tt.name, r, spikesWithGC, spikes, tt.r, tt.spikesWithGC, tt.spikes)
+		}
+	}
+}
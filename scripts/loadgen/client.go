@@ -0,0 +1,342 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Errors mirrors the socket error categories wrk reports, plus responses
+// outside the 2xx/3xx range.
+type Errors struct {
+	Connect int64 `json:"connect"`
+	Read    int64 `json:"read"`
+	Write   int64 `json:"write"`
+	Timeout int64 `json:"timeout"`
+	Status  int64 `json:"status"`
+}
+
+func (e Errors) total() int64 {
+	return e.Connect + e.Read + e.Write + e.Timeout + e.Status
+}
+
+func (e *Errors) add(other Errors) {
+	e.Connect += other.Connect
+	e.Read += other.Read
+	e.Write += other.Write
+	e.Timeout += other.Timeout
+	e.Status += other.Status
+}
+
+// RunStats is the aggregate outcome of one load run.
+type RunStats struct {
+	Elapsed   time.Duration
+	Requests  int64
+	BytesRead int64
+	Errors    Errors
+	Latency   *Histogram
+	Timing    *TimingBreakdown
+	Seconds   []SecondBucket
+	Heatmap   *Heatmap
+}
+
+type workerStats struct {
+	requests  int64
+	bytesRead int64
+	errors    Errors
+	latency   *Histogram
+	seconds   *secondRecorder
+}
+
+// fail counts n errors of one category, both in the run totals and in the
+// current second of the timeline.
+func (ws *workerStats) fail(counter *int64, n int64) {
+	*counter += n
+	ws.seconds.fail(time.Now(), n)
+}
+
+// countingReader counts bytes read from the connection so the transfer rate
+// includes headers, as wrk's does.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+func buildRequest(cfg *Config) ([]byte, string, error) {
+	u, err := url.Parse(cfg.URL)
+	if err != nil {
+		return nil, "", err
+	}
+
+	addr := u.Host
+	if u.Port() == "" {
+		addr = net.JoinHostPort(u.Hostname(), "80")
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%s %s HTTP/1.1\r\n", strings.ToUpper(cfg.Method), u.RequestURI())
+	fmt.Fprintf(&buf, "Host: %s\r\n", u.Host)
+	buf.WriteString("User-Agent: loadgen\r\n")
+	for _, h := range cfg.Headers {
+		name, value, _ := strings.Cut(h, ":")
+		fmt.Fprintf(&buf, "%s: %s\r\n", strings.TrimSpace(name), strings.TrimSpace(value))
+	}
+	if cfg.Body != "" || cfg.Method == http.MethodPost || cfg.Method == http.MethodPut {
+		fmt.Fprintf(&buf, "Content-Length: %d\r\n", len(cfg.Body))
+	}
+	buf.WriteString("\r\n")
+	buf.WriteString(cfg.Body)
+
+	return buf.Bytes(), addr, nil
+}
+
+func run(cfg *Config) (*RunStats, error) {
+	return runUntil(cfg, nil)
+}
+
+// runUntil is run with an early stop: once a second, done is given the
+// completed seconds so far, and the load ends before cfg.Duration is up as
+// soon as it returns true. done runs on another goroutine, which has
+// finished by the time runUntil returns. A nil done runs for the full
+// duration.
+func runUntil(cfg *Config, done func([]SecondBucket) bool) (*RunStats, error) {
+	request, addr, err := buildRequest(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fail fast if nothing is listening rather than reporting a run made
+	// entirely of connect errors.
+	probe, err := net.DialTimeout("tcp", addr, cfg.Timeout)
+	if err != nil {
+		return nil, fmt.Errorf("cannot connect to %s: %v", addr, err)
+	}
+	probe.Close()
+
+	results := make([]*workerStats, cfg.Connections)
+	var wg sync.WaitGroup
+
+	start := time.Now()
+	deadline := start.Add(cfg.Duration)
+	seconds := newTimeline(start, cfg.Duration)
+
+	// stop is closed once, by whichever of an early stop and the end of the
+	// run comes first; early records which one it was
+	stop := make(chan struct{})
+	var stopOnce sync.Once
+	early := false
+	watched := make(chan struct{})
+	if done != nil {
+		stopEarly := func() {
+			stopOnce.Do(func() {
+				early = true
+				close(stop)
+			})
+		}
+		go func() {
+			defer close(watched)
+			watchSeconds(seconds, deadline, stop, stopEarly, done)
+		}()
+	} else {
+		close(watched)
+	}
+
+	var traces *tracer
+	traced := make(chan struct{})
+	if cfg.TraceRate > 0 {
+		go func() {
+			defer close(traced)
+			traces = sampleTimings(cfg, deadline, stop)
+		}()
+	} else {
+		close(traced)
+	}
+
+	for i := range results {
+		ws := &workerStats{
+			latency: NewHistogram(),
+			seconds: newSecondRecorder(seconds),
+		}
+		results[i] = ws
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			runConnection(cfg, addr, request, deadline, stop, ws)
+			ws.seconds.flush()
+		}()
+	}
+	wg.Wait()
+
+	elapsed := time.Since(start)
+	stopOnce.Do(func() { close(stop) })
+	// done runs on the watcher; it has returned once watched is closed
+	<-watched
+	if early {
+		// Stopped early: drop the seconds the run never reached
+		seconds.truncate(int((elapsed + time.Second - 1) / time.Second))
+	}
+
+	stats := &RunStats{
+		Elapsed: elapsed,
+		Latency: NewHistogram(),
+		Seconds: seconds.buckets(),
+		Heatmap: seconds.heatmap(),
+	}
+	for _, ws := range results {
+		stats.Requests += ws.requests
+		stats.BytesRead += ws.bytesRead
+		stats.Errors.add(ws.errors)
+		stats.Latency.Merge(ws.latency)
+	}
+
+	<-traced
+	if traces != nil {
+		stats.Timing = traces.breakdown()
+	}
+	return stats, nil
+}
+
+// watchSeconds hands done the completed seconds of the run once a second
+// and calls stopEarly when it returns true. Workers merge a second into the
+// timeline when they move on to the next one, so each check waits a moment
+// past the second boundary.
+func watchSeconds(seconds *timeline, deadline time.Time, stop <-chan struct{}, stopEarly func(), done func([]SecondBucket) bool) {
+	for completed := 1; ; completed++ {
+		select {
+		case <-stop:
+			return
+		case <-time.After(time.Until(seconds.start.Add(time.Duration(completed)*time.Second + 50*time.Millisecond))):
+		}
+		if !time.Now().Before(deadline) {
+			return
+		}
+		if done(seconds.buckets()[:completed]) {
+			stopEarly()
+			return
+		}
+	}
+}
+
+// runConnection drives one connection until the deadline, or until stop is
+// closed, keeping up to cfg.Pipeline requests in flight. HTTP/1.1 answers
+// pipelined requests in order, so the oldest send time in the queue always
+// belongs to the response being read; latency is measured from that request's
+// write to the end of its response body.
+func runConnection(cfg *Config, addr string, request []byte, deadline time.Time, stop <-chan struct{}, ws *workerStats) {
+	batch := bytes.Repeat(request, cfg.Pipeline)
+	inflight := make([]time.Time, 0, cfg.Pipeline)
+	running := func(now time.Time) bool {
+		select {
+		case <-stop:
+			return false
+		default:
+			return now.Before(deadline)
+		}
+	}
+
+	for running(time.Now()) {
+		conn, err := net.DialTimeout("tcp", addr, cfg.Timeout)
+		if err != nil {
+			ws.fail(&ws.errors.Connect, 1)
+			time.Sleep(10 * time.Millisecond)
+			continue
+		}
+
+		counter := &countingReader{r: conn}
+		reader := bufio.NewReaderSize(counter, 64*1024)
+		inflight = inflight[:0]
+
+		// Prime the connection with a full pipeline in a single write.
+		sent := time.Now()
+		if _, err := conn.Write(batch); err != nil {
+			ws.fail(&ws.errors.Write, 1)
+			conn.Close()
+			continue
+		}
+		for i := 0; i < cfg.Pipeline; i++ {
+			inflight = append(inflight, sent)
+		}
+
+		for len(inflight) > 0 {
+			conn.SetReadDeadline(time.Now().Add(cfg.Timeout))
+			resp, err := http.ReadResponse(reader, nil)
+			if err == nil {
+				_, err = io.Copy(io.Discard, resp.Body)
+				resp.Body.Close()
+			}
+			if err != nil {
+				if errors.Is(err, os.ErrDeadlineExceeded) {
+					ws.fail(&ws.errors.Timeout, int64(len(inflight)))
+				} else {
+					ws.fail(&ws.errors.Read, int64(len(inflight)))
+				}
+				break
+			}
+
+			now := time.Now()
+			latency := now.Sub(inflight[0])
+			ws.latency.Record(latency)
+			ws.seconds.record(now, latency)
+			ws.requests++
+			if resp.StatusCode > 399 {
+				ws.fail(&ws.errors.Status, 1)
+			}
+			copy(inflight, inflight[1:])
+			inflight = inflight[:len(inflight)-1]
+
+			if resp.Close {
+				// The server will not answer anything queued behind this.
+				ws.fail(&ws.errors.Read, int64(len(inflight)))
+				break
+			}
+
+			if running(now) {
+				if _, err := conn.Write(request); err != nil {
+					ws.fail(&ws.errors.Write, 1)
+					break
+				}
+				inflight = append(inflight, time.Now())
+			}
+		}
+
+		ws.bytesRead += counter.n
+		conn.Close()
+	}
+}
+
+func formatLatency(us float64) string {
+	switch {
+	case us >= 1e6:
+		return strconv.FormatFloat(us/1e6, 'f', 2, 64) + "s"
+	case us >= 1e3:
+		return strconv.FormatFloat(us/1e3, 'f', 2, 64) + "ms"
+	default:
+		return strconv.FormatFloat(us, 'f', 2, 64) + "us"
+	}
+}
+
+func formatBytes(n float64) string {
+	units := []string{"B", "KB", "MB", "GB", "TB"}
+	i := 0
+	for n >= 1024 && i < len(units)-1 {
+		n /= 1024
+		i++
+	}
+	return strconv.FormatFloat(n, 'f', 2, 64) + units[i]
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// heatmapBounds are the exclusive upper bounds of the latency bands in a
+// heatmap: powers of two from 32µs to ~16.8s.
+var heatmapBounds = func() []int64 {
+	var bounds []int64
+	for us := int64(32); us <= 1<<24; us <<= 1 {
+		bounds = append(bounds, us)
+	}
+	return bounds
+}()
+
+// Heatmap counts the requests of each second of a run per latency band.
+// Counts[s][b] is the number of responses completed in second s+1 whose
+// latency fell below BoundsUs[b] and at or above BoundsUs[b-1].
+type Heatmap struct {
+	BoundsUs []int64   `json:"bounds_us"`
+	Counts   [][]int64 `json:"counts"`
+}
+
+func (t *timeline) heatmap() *Heatmap {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	h := &Heatmap{BoundsUs: heatmapBounds}
+	for _, latency := range t.latency {
+		h.Counts = append(h.Counts, latency.Bands(heatmapBounds))
+	}
+	return h
+}
+
+// heatmapShades are the cell intensities of the terminal heatmap, lowest
+// first.
+var heatmapShades = []rune(" ░▒▓█")
+
+// maxHeatmapColumns caps the terminal heatmap width; longer runs are folded
+// so that each column covers several seconds.
+const maxHeatmapColumns = 60
+
+// renderHeatmap draws the heatmap with block characters, slowest band on
+// top. Intensity is log-scaled so that a handful of outliers still show up
+// next to the bulk of the requests.
+func renderHeatmap(h *Heatmap) string {
+	if h == nil || len(h.Counts) == 0 {
+		return ""
+	}
+
+	width := (len(h.Counts) + maxHeatmapColumns - 1) / maxHeatmapColumns
+	var columns [][]int64
+	for start := 0; start < len(h.Counts); start += width {
+		column := make([]int64, len(h.BoundsUs))
+		for s := start; s < start+width && s < len(h.Counts); s++ {
+			for b, c := range h.Counts[s] {
+				column[b] += c
+			}
+		}
+		columns = append(columns, column)
+	}
+
+	lowest, highest, peak := len(h.BoundsUs), -1, int64(0)
+	for _, column := range columns {
+		for b, c := range column {
+			if c == 0 {
+				continue
+			}
+			lowest = min(lowest, b)
+			highest = max(highest, b)
+			peak = max(peak, c)
+		}
+	}
+	if highest < 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "  Latency heatmap (%ds per column, log-scaled counts)\n", width)
+	for band := highest; band >= lowest; band-- {
+		label := "<" + formatLatency(float64(h.BoundsUs[band]))
+		if band == len(h.BoundsUs)-1 {
+			label = "≥" + formatLatency(float64(h.BoundsUs[band-1]))
+		}
+		fmt.Fprintf(&b, "  %9s │", label)
+		for _, column := range columns {
+			b.WriteRune(heatmapShade(column[band], peak))
+		}
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(&b, "  %9s └%s", "", strings.Repeat("─", len(columns)))
+
+	return b.String()
+}
+
+func heatmapShade(count, peak int64) rune {
+	if count == 0 {
+		return heatmapShades[0]
+	}
+	level := math.Log1p(float64(count)) / math.Log1p(float64(peak))
+	i := 1 + int(level*float64(len(heatmapShades)-2)+0.5)
+	return heatmapShades[min(i, len(heatmapShades)-1)]
+}
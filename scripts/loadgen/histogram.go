@@ -0,0 +1,182 @@
+package main
+
+import (
+	"math"
+	"math/bits"
+	"time"
+)
+
+// Histogram is a log-linear latency histogram in the style of HdrHistogram.
+// Values are recorded in microseconds; every power-of-two range is split into
+// 64 linear sub-buckets, which keeps the relative error below 1.6% from 1µs
+// up to 2^39µs, about six days; longer values land in the last bucket.
+type Histogram struct {
+	counts []int64
+	count  int64
+	sum    int64
+	min    int64
+	max    int64
+}
+
+const (
+	subBucketBits  = 7
+	subBucketCount = 1 << subBucketBits
+	subBucketHalf  = subBucketCount / 2
+	maxShift       = 32
+	bucketSlots    = subBucketCount + maxShift*subBucketHalf
+)
+
+func NewHistogram() *Histogram {
+	return &Histogram{
+		counts: make([]int64, bucketSlots),
+		min:    math.MaxInt64,
+	}
+}
+
+func bucketIndex(v int64) int {
+	if v < subBucketCount {
+		return int(v)
+	}
+	shift := bits.Len64(uint64(v)) - subBucketBits
+	if shift > maxShift {
+		return bucketSlots - 1
+	}
+	top := v >> uint(shift)
+	return subBucketCount + (shift-1)*subBucketHalf + int(top-subBucketHalf)
+}
+
+// bucketUpperBound returns the largest value that maps to bucket i.
+func bucketUpperBound(i int) int64 {
+	if i < subBucketCount {
+		return int64(i)
+	}
+	shift := (i-subBucketCount)/subBucketHalf + 1
+	top := int64((i-subBucketCount)%subBucketHalf + subBucketHalf)
+	return (top+1)<<uint(shift) - 1
+}
+
+func (h *Histogram) Record(d time.Duration) {
+	v := d.Microseconds()
+	if v < 0 {
+		v = 0
+	}
+	h.counts[bucketIndex(v)]++
+	h.count++
+	h.sum += v
+	if v < h.min {
+		h.min = v
+	}
+	if v > h.max {
+		h.max = v
+	}
+}
+
+func (h *Histogram) Reset() {
+	for i := range h.counts {
+		h.counts[i] = 0
+	}
+	h.count = 0
+	h.sum = 0
+	h.min = math.MaxInt64
+	h.max = 0
+}
+
+func (h *Histogram) Merge(other *Histogram) {
+	if other.count == 0 {
+		return
+	}
+	for i, c := range other.counts {
+		h.counts[i] += c
+	}
+	h.count += other.count
+	h.sum += other.sum
+	if other.min < h.min {
+		h.min = other.min
+	}
+	if other.max > h.max {
+		h.max = other.max
+	}
+}
+
+func (h *Histogram) Count() int64 {
+	return h.count
+}
+
+// Mean returns the mean latency in microseconds.
+func (h *Histogram) Mean() float64 {
+	if h.count == 0 {
+		return 0
+	}
+	return float64(h.sum) / float64(h.count)
+}
+
+// StdDev returns the standard deviation in microseconds, taking every value
+// as the midpoint of its bucket.
+func (h *Histogram) StdDev() float64 {
+	if h.count == 0 {
+		return 0
+	}
+	mean := h.Mean()
+	var squares float64
+	for i, c := range h.counts {
+		if c == 0 {
+			continue
+		}
+		lower := int64(0)
+		if i > 0 {
+			lower = bucketUpperBound(i-1) + 1
+		}
+		d := float64(lower+bucketUpperBound(i))/2 - mean
+		squares += d * d * float64(c)
+	}
+	return math.Sqrt(squares / float64(h.count))
+}
+
+// Max returns the largest recorded latency in microseconds.
+func (h *Histogram) Max() int64 {
+	return h.max
+}
+
+// Percentile returns the latency in microseconds at or below which q percent
+// of the recorded values fall.
+func (h *Histogram) Percentile(q float64) int64 {
+	if h.count == 0 {
+		return 0
+	}
+	target := int64(math.Ceil(q / 100 * float64(h.count)))
+	if target < 1 {
+		target = 1
+	}
+
+	var seen int64
+	for i, c := range h.counts {
+		seen += c
+		if seen >= target {
+			upper := bucketUpperBound(i)
+			if upper > h.max {
+				return h.max
+			}
+			return upper
+		}
+	}
+	return h.max
+}
+
+// Bands folds the histogram into coarse bands: band i counts the values
+// below bounds[i] (and at or above bounds[i-1]); the last band also takes
+// everything above the last bound.
+func (h *Histogram) Bands(bounds []int64) []int64 {
+	bands := make([]int64, len(bounds))
+	band := 0
+	for i, c := range h.counts {
+		if c == 0 {
+			continue
+		}
+		upper := bucketUpperBound(i)
+		for band < len(bounds)-1 && upper >= bounds[band] {
+			band++
+		}
+		bands[band] += c
+	}
+	return bands
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestBucketIndex(t *testing.T) {
+	tests := []struct {
+		v    int64
+		want int
+	}{
+		{0, 0},
+		{1, 1},
+		{127, 127},
+		// From 128 on, every power of two is split into 64 buckets
+		{128, 128},
+		{129, 128},
+		{130, 129},
+		{255, 191},
+		{256, 192},
+		{259, 192},
+		{260, 193},
+		// The top of the range, 2^39µs, and everything past it share the
+		// last bucket
+		{1<<39 - 1, bucketSlots - 1},
+		{1 << 39, bucketSlots - 1},
+		{math.MaxInt64, bucketSlots - 1},
+	}
+	for _, tt := range tests {
+		if got := bucketIndex(tt.v); got != tt.want {
+			t.Errorf("bucketIndex(%d) = %d, want %d", tt.v, got, tt.want)
+		}
+	}
+}
+
+func TestBucketBounds(t *testing.T) {
+	if got, want := bucketUpperBound(bucketSlots-1), int64(1<<39-1); got != want {
+		t.Errorf("upper bound of the last bucket = %d, want %d", got, want)
+	}
+
+	lower := int64(0)
+	for i := 0; i < bucketSlots; i++ {
+		upper := bucketUpperBound(i)
+		if upper < lower {
+			t.Fatalf("bucket %d: upper bound %d below lower bound %d", i, upper, lower)
+		}
+		if got := bucketIndex(lower); got != i {
+			t.Fatalf("bucketIndex(%d) = %d, want %d (lower bound of bucket %d)", lower, got, i, i)
+		}
+		if got := bucketIndex(upper); got != i {
+			t.Fatalf("bucketIndex(%d) = %d, want %d (upper bound of bucket %d)", upper, got, i, i)
+		}
+		// Values below subBucketCount have a bucket each
+		if width := float64(upper - lower + 1); lower >= subBucketCount && width/float64(lower) > 1.0/64 {
+			t.Fatalf("bucket %d [%d, %d]: relative width %.4f above 1/64", i, lower, upper, width/float64(lower))
+		}
+		lower = upper + 1
+	}
+}
+
+func record(h *Histogram, us int64, n int) {
+	for i := 0; i < n; i++ {
+		h.Record(time.Duration(us) * time.Microsecond)
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	uniform := NewHistogram()
+	for v := int64(1); v <= 100; v++ {
+		record(uniform, v, 1)
+	}
+
+	// 90% fast, 9% slow and one outlier
+	bimodal := NewHistogram()
+	record(bimodal, 100, 90)
+	record(bimodal, 5000, 9)
+	record(bimodal, 10000, 1)
+
+	overflow := NewHistogram()
+	record(overflow, 10, 1)
+	record(overflow, int64(10*24*time.Hour/time.Microsecond), 1)
+
+	tests := []struct {
+		name string
+		h    *Histogram
+		q    float64
+		want int64
+	}{
+		{"empty", NewHistogram(), 99, 0},
+		{"uniform p0", uniform, 0, 1},
+		{"uniform p50", uniform, 50, 50},
+		{"uniform p99", uniform, 99, 99},
+		{"uniform p99.9", uniform, 99.9, 100},
+		{"uniform p100", uniform, 100, 100},
+		{"bimodal p50", bimodal, 50, 100},
+		{"bimodal p90", bimodal, 90, 100},
+		// 5000 falls in the bucket [4992, 5055]
+		{"bimodal p91", bimodal, 91, 5055},
+		{"bimodal p99", bimodal, 99, 5055},
+		// The last bucket is clamped to the largest recorded value
+		{"bimodal p100", bimodal, 100, 10000},
+		{"overflow p50", overflow, 50, 10},
+		{"overflow p100", overflow, 100, 1<<39 - 1},
+	}
+	for _, tt := range tests {
+		if got := tt.h.Percentile(tt.q); got != tt.want {
+			t.Errorf("%s: Percentile(%v) = %d, want %d", tt.name, tt.q, got, tt.want)
+		}
+	}
+}
+
+func TestBands(t *testing.T) {
+	h := NewHistogram()
+	for _, v := range []int64{500, 999, 1000, 5000, 50000, 500000} {
+		record(h, v, 1)
+	}
+	got := h.Bands([]int64{1000, 10000, 100000})
+	want := []int64{2, 2, 2}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Bands = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestStats(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []int64
+		mean   float64
+		stddev float64
+		max    int64
+	}{
+		{"empty", nil, 0, 0, 0},
+		{"flat", []int64{50, 50, 50}, 50, 0, 50},
+		{"two values", []int64{10, 30}, 20, 10, 30},
+	}
+	for _, tt := range tests {
+		h := NewHistogram()
+		for _, v := range tt.values {
+			record(h, v, 1)
+		}
+		if got := h.Mean(); got != tt.mean {
+			t.Errorf("%s: Mean = %v, want %v", tt.name, got, tt.mean)
+		}
+		if got := h.StdDev(); got != tt.stddev {
+			t.Errorf("%s: StdDev = %v, want %v", tt.name, got, tt.stddev)
+		}
+		if got := h.Max(); got != tt.max {
+			t.Errorf("%s: Max = %v, want %v", tt.name, got, tt.max)
+		}
+	}
+}
+
+func TestMerge(t *testing.T) {
+	a, b := NewHistogram(), NewHistogram()
+	record(a, 10, 3)
+	record(b, 1000, 1)
+	a.Merge(b)
+	a.Merge(NewHistogram())
+
+	if a.Count() != 4 || a.Max() != 1000 || a.Percentile(0) != 10 || a.Mean() != 257.5 {
+		t.Errorf("merged: count %d, max %d, min %d, mean %v", a.Count(), a.Max(), a.Percentile(0), a.Mean())
+	}
+
+	a.Reset()
+	if a.Count() != 0 || a.Max() != 0 || a.Percentile(50) != 0 {
+		t.Errorf("reset: count %d, max %d, p50 %d", a.Count(), a.Max(), a.Percentile(50))
+	}
+}
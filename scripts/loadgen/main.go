@@ -0,0 +1,214 @@
+// Command loadgen is the Go load generator used by scripts/benchmark.sh for
+// tests that wrk cannot express, such as HTTP/1.1 pipelining.
+//
+// It speaks HTTP/1.1 directly over TCP so that several requests can be kept
+// in flight on one connection, and writes a single endpoint result in the
+// same JSON shape the wrk parser in benchmark.sh produces.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+)
+
+type headerFlags []string
+
+func (h *headerFlags) String() string {
+	return strings.Join(*h, ", ")
+}
+
+func (h *headerFlags) Set(value string) error {
+	if !strings.Contains(value, ":") {
+		return fmt.Errorf("header %q must be in 'Name: value' form", value)
+	}
+	*h = append(*h, value)
+	return nil
+}
+
+type Config struct {
+	Endpoint    string
+	URL         string
+	Method      string
+	Body        string
+	Headers     headerFlags
+	Connections int
+	Threads     int
+	Duration    time.Duration
+	Pipeline    int
+	Timeout     time.Duration
+	Output      string
+	Hgrm        string
+
+	SlowClients  int
+	SlowInterval time.Duration
+
+	TraceRate int
+
+	ShutdownPID   int
+	ShutdownAfter time.Duration
+
+	StableWindow int
+	StableRPSCV  float64
+	StableP99CV  float64
+	StableMin    time.Duration
+}
+
+func main() {
+	var cfg Config
+
+	flag.StringVar(&cfg.Endpoint, "name", "", "endpoint label stored in the result (defaults to the URL path)")
+	flag.StringVar(&cfg.URL, "url", "http://localhost:8080/", "target URL")
+	flag.StringVar(&cfg.Method, "method", "GET", "HTTP method")
+	flag.StringVar(&cfg.Body, "body", "", "request body")
+	flag.Var(&cfg.Headers, "H", "extra request header, may be repeated (e.g. -H 'Content-Type: application/json')")
+	flag.IntVar(&cfg.Connections, "c", 100, "number of connections")
+	flag.IntVar(&cfg.Threads, "t", 4, "number of OS threads (GOMAXPROCS)")
+	flag.DurationVar(&cfg.Duration, "d", 30*time.Second, "test duration")
+	flag.IntVar(&cfg.Pipeline, "pipeline", 1, "requests kept in flight per connection (1 disables pipelining)")
+	flag.DurationVar(&cfg.Timeout, "timeout", 10*time.Second, "per-response read timeout")
+	flag.StringVar(&cfg.Output, "o", "", "write the JSON result to this file instead of stdout")
+	flag.StringVar(&cfg.Hgrm, "hgrm", "", "also write an HdrHistogram-style percentile distribution to this file")
+	flag.IntVar(&cfg.TraceRate, "trace-rate", 0, "traced sample requests per second for the timing breakdown (0 disables)")
+	flag.IntVar(&cfg.SlowClients, "slow-clients", 0, "run a baseline, then repeat it with this many slow clients attached")
+	flag.DurationVar(&cfg.SlowInterval, "slow-interval", 500*time.Millisecond, "delay between bytes trickled or read by slow clients")
+	flag.IntVar(&cfg.ShutdownPID, "shutdown-pid", 0, "send this server process SIGTERM mid-run and measure how it drains (0 disables)")
+	flag.DurationVar(&cfg.ShutdownAfter, "shutdown-after", 0, "time under load before SIGTERM is sent (defaults to half the duration)")
+	flag.IntVar(&cfg.StableWindow, "until-stable", 0, "warm-up mode: stop once the last N seconds are stable, running at most the duration (0 disables)")
+	flag.Float64Var(&cfg.StableRPSCV, "stable-rps-cv", 0.05, "largest coefficient of variation of requests/sec that counts as stable")
+	flag.Float64Var(&cfg.StableP99CV, "stable-p99-cv", 0.2, "largest coefficient of variation of P99 that counts as stable")
+	flag.DurationVar(&cfg.StableMin, "min-duration", 0, "warm-up mode: run at least this long")
+	flag.Parse()
+
+	if err := cfg.validate(); err != nil {
+		log.Fatalf("loadgen: %v", err)
+	}
+
+	runtime.GOMAXPROCS(cfg.Threads)
+
+	var stats *RunStats
+	var slow *SlowClientResult
+	var shutdown *ShutdownResult
+	var warmup *WarmupResult
+	var err error
+	if cfg.SlowClients > 0 {
+		stats, slow, err = runSlowClientTest(&cfg)
+	} else if cfg.ShutdownPID > 0 {
+		stats, shutdown, err = runShutdownTest(&cfg)
+	} else if cfg.StableWindow > 0 {
+		stats, warmup, err = runWarmup(&cfg)
+	} else {
+		stats, err = run(&cfg)
+	}
+	if err != nil {
+		log.Fatalf("loadgen: %v", err)
+	}
+
+	result := newEndpointResult(&cfg, stats)
+	if slow != nil {
+		result.SlowClient = slow
+		result.RawOutput += "\n" + slowClientSummary(slow)
+	}
+	if shutdown != nil {
+		result.Shutdown = shutdown
+		result.RawOutput += "\n" + shutdownSummary(shutdown)
+	}
+	if warmup != nil {
+		result.Warmup = warmup
+		result.RawOutput += "\n" + warmupSummary(warmup)
+	}
+	fmt.Fprintln(os.Stderr, result.RawOutput)
+	if heatmap := renderHeatmap(result.Heatmap); heatmap != "" {
+		fmt.Fprintln(os.Stderr, heatmap)
+	}
+
+	if cfg.Hgrm != "" {
+		if err := writeDistribution(cfg.Hgrm, stats.Latency); err != nil {
+			log.Fatalf("loadgen: %v", err)
+		}
+	}
+
+	if err := writeResult(cfg.Output, result); err != nil {
+		log.Fatalf("loadgen: %v", err)
+	}
+}
+
+func (cfg *Config) validate() error {
+	u, err := url.Parse(cfg.URL)
+	if err != nil {
+		return fmt.Errorf("invalid url: %v", err)
+	}
+	if u.Scheme != "http" {
+		return fmt.Errorf("only http:// URLs are supported, got %q", cfg.URL)
+	}
+	if cfg.Connections < 1 {
+		return fmt.Errorf("connections must be at least 1")
+	}
+	if cfg.Threads < 1 {
+		return fmt.Errorf("threads must be at least 1")
+	}
+	if cfg.Pipeline < 1 {
+		return fmt.Errorf("pipeline depth must be at least 1")
+	}
+	if cfg.Duration <= 0 {
+		return fmt.Errorf("duration must be positive")
+	}
+	if cfg.TraceRate < 0 {
+		return fmt.Errorf("trace rate must not be negative")
+	}
+	if cfg.SlowClients < 0 {
+		return fmt.Errorf("slow clients must not be negative")
+	}
+	if cfg.SlowInterval <= 0 {
+		return fmt.Errorf("slow interval must be positive")
+	}
+	if cfg.ShutdownPID < 0 {
+		return fmt.Errorf("shutdown pid must not be negative")
+	}
+	if cfg.ShutdownPID > 0 {
+		if cfg.SlowClients > 0 || cfg.Pipeline > 1 {
+			return fmt.Errorf("the shutdown test runs without slow clients or pipelining")
+		}
+		if cfg.ShutdownAfter == 0 {
+			cfg.ShutdownAfter = cfg.Duration / 2
+		}
+		if cfg.ShutdownAfter < 0 || cfg.ShutdownAfter >= cfg.Duration {
+			return fmt.Errorf("shutdown-after must be between 0 and the duration")
+		}
+	}
+	if cfg.StableWindow < 0 {
+		return fmt.Errorf("stable window must not be negative")
+	}
+	if cfg.StableWindow > 0 {
+		if cfg.SlowClients > 0 || cfg.ShutdownPID > 0 {
+			return fmt.Errorf("warm-up mode runs without slow clients or a shutdown test")
+		}
+		if cfg.StableRPSCV <= 0 || cfg.StableP99CV <= 0 {
+			return fmt.Errorf("stable coefficients of variation must be positive")
+		}
+	}
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = u.RequestURI()
+	}
+	return nil
+}
+
+func writeResult(path string, result *EndpointResult) error {
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	if path == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
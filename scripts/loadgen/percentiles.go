@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"os"
+)
+
+// spectrumPercentiles are the percentiles stored in percentile_spectrum.
+// scripts/wrk/percentiles.lua reports the same list for wrk runs.
+var spectrumPercentiles = []float64{0, 10, 20, 30, 40, 50, 60, 70, 75, 80, 90, 95, 97.5, 99, 99.5, 99.9, 99.95, 99.99, 99.999, 100}
+
+type PercentilePoint struct {
+	Percentile float64 `json:"percentile"`
+	LatencyUs  int64   `json:"latency_us"`
+}
+
+func spectrum(h *Histogram) []PercentilePoint {
+	points := make([]PercentilePoint, 0, len(spectrumPercentiles))
+	for _, q := range spectrumPercentiles {
+		points = append(points, PercentilePoint{Percentile: q, LatencyUs: h.Percentile(q)})
+	}
+	return points
+}
+
+// writeDistribution writes the latency distribution in HdrHistogram's
+// percentile distribution format (values in milliseconds), which the
+// HdrHistogram plotter and similar tools read directly. Percentile steps
+// halve every time the remaining tail halves, five steps per halving.
+func writeDistribution(path string, h *Histogram) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+
+	count := h.Count()
+	fmt.Fprintf(w, "%12s %14s %10s %14s\n\n", "Value", "Percentile", "TotalCount", "1/(1-Percentile)")
+	for level := 0.0; ; {
+		if level >= 100 || math.Ceil(level/100*float64(count)) >= float64(count) {
+			fmt.Fprintf(w, "%12.3f %1.12f %10d\n", float64(h.Max())/1000, 1.0, count)
+			break
+		}
+		fmt.Fprintf(w, "%12.3f %1.12f %10d %14.2f\n",
+			float64(h.Percentile(level))/1000, level/100, max(1, int64(math.Ceil(level/100*float64(count)))), 100/(100-level))
+
+		halvings := math.Floor(math.Log2(100/(100-level))) + 1
+		level += 100 / 5 / math.Pow(2, halvings)
+	}
+	fmt.Fprintf(w, "#[Mean    = %12.3f, StdDeviation   = %12.3f]\n", h.Mean()/1000, h.StdDev()/1000)
+	fmt.Fprintf(w, "#[Max     = %12.3f, Total count    = %12d]\n", float64(h.Max())/1000, count)
+
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestWriteDistribution(t *testing.T) {
+	h := NewHistogram()
+	for v := int64(1); v <= 1000; v++ {
+		record(h, v, 1)
+	}
+	path := filepath.Join(t.TempDir(), "distribution.hgrm")
+	if err := writeDistribution(path, h); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if !strings.HasPrefix(strings.TrimSpace(lines[0]), "Value") {
+		t.Fatalf("header = %q", lines[0])
+	}
+	if want := "#[Max     =        1.000, Total count    =         1000]"; lines[len(lines)-1] != want {
+		t.Errorf("last line = %q, want %q", lines[len(lines)-1], want)
+	}
+
+	// Values, percentiles and counts never decrease, and the last row is
+	// the maximum at percentile 1
+	var value, percentile, count float64
+	rows := 0
+	for _, line := range lines[2 : len(lines)-2] {
+		fields := strings.Fields(line)
+		v, _ := strconv.ParseFloat(fields[0], 64)
+		p, _ := strconv.ParseFloat(fields[1], 64)
+		c, _ := strconv.ParseFloat(fields[2], 64)
+		if v < value || p <= percentile && rows > 0 || c < count {
+			t.Fatalf("row %q goes back after (%v, %v, %v)", line, value, percentile, count)
+		}
+		value, percentile, count = v, p, c
+		rows++
+	}
+	if value != 1 || percentile != 1 || count != 1000 {
+		t.Errorf("last row = (%v, %v, %v), want (1, 1, 1000)", value, percentile, count)
+	}
+	// Five steps per halving of the tail down to 1/1000
+	if rows < 40 {
+		t.Errorf("%d rows, want at least 40", rows)
+	}
+}
+
+func TestWriteDistributionEmpty(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "distribution.hgrm")
+	if err := writeDistribution(path, NewHistogram()); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "Total count    =            0]") {
+		t.Errorf("empty distribution:\n%s", data)
+	}
+}
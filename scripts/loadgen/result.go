@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// EndpointResult matches the endpoint objects benchmark.sh writes for wrk
+// runs (see EndpointResult in scripts/generate_readme.go), so loadgen output
+// can be dropped into the same results file.
+type EndpointResult struct {
+	Endpoint           string             `json:"endpoint"`
+	URL                string             `json:"url"`
+	RequestsPerSec     string             `json:"requests_per_sec"`
+	AvgLatency         string             `json:"avg_latency"`
+	TransferPerSec     string             `json:"transfer_per_sec"`
+	LatencyPercentiles LatencyPercentiles `json:"latency_percentiles"`
+	PercentileSpectrum []PercentilePoint  `json:"percentile_spectrum"`
+	DistributionFile   string             `json:"distribution_file,omitempty"`
+	RawOutput          string             `json:"raw_output"`
+	LoadGenerator      string             `json:"load_generator"`
+	PipelineDepth      int                `json:"pipeline_depth"`
+	Requests           int64              `json:"requests"`
+	Errors             Errors             `json:"errors"`
+	SlowClient         *SlowClientResult  `json:"slow_client,omitempty"`
+	Shutdown           *ShutdownResult    `json:"shutdown,omitempty"`
+	Warmup             *WarmupResult      `json:"warmup,omitempty"`
+	TimingBreakdown    *TimingBreakdown   `json:"timing_breakdown,omitempty"`
+	Timeseries         []SecondBucket     `json:"timeseries"`
+	Heatmap            *Heatmap           `json:"heatmap"`
+}
+
+type LatencyPercentiles struct {
+	P50   string `json:"50%"`
+	P75   string `json:"75%"`
+	P90   string `json:"90%"`
+	P99   string `json:"99%"`
+	P999  string `json:"99.9%"`
+	P9999 string `json:"99.99%"`
+	Max   string `json:"max"`
+}
+
+func newEndpointResult(cfg *Config, stats *RunStats) *EndpointResult {
+	seconds := stats.Elapsed.Seconds()
+	h := stats.Latency
+
+	result := &EndpointResult{
+		Endpoint:       cfg.Endpoint,
+		URL:            cfg.URL,
+		RequestsPerSec: fmt.Sprintf("%.2f", float64(stats.Requests)/seconds),
+		AvgLatency:     formatLatency(h.Mean()),
+		TransferPerSec: formatBytes(float64(stats.BytesRead) / seconds),
+		LatencyPercentiles: LatencyPercentiles{
+			P50:   formatLatency(float64(h.Percentile(50))),
+			P75:   formatLatency(float64(h.Percentile(75))),
+			P90:   formatLatency(float64(h.Percentile(90))),
+			P99:   formatLatency(float64(h.Percentile(99))),
+			P999:  formatLatency(float64(h.Percentile(99.9))),
+			P9999: formatLatency(float64(h.Percentile(99.99))),
+			Max:   formatLatency(float64(h.Max())),
+		},
+		PercentileSpectrum: spectrum(h),
+		DistributionFile:   cfg.Hgrm,
+		LoadGenerator:      "loadgen",
+		PipelineDepth:      cfg.Pipeline,
+		Requests:           stats.Requests,
+		Errors:             stats.Errors,
+		TimingBreakdown:    stats.Timing,
+		Timeseries:         stats.Seconds,
+		Heatmap:            stats.Heatmap,
+	}
+	result.RawOutput = summary(cfg, stats, result)
+	return result
+}
+
+// summary renders a wrk-like text report for the raw_output field and the
+// terminal.
+func summary(cfg *Config, stats *RunStats, result *EndpointResult) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Running %s test @ %s\n", cfg.Duration, cfg.URL)
+	fmt.Fprintf(&b, "  %d threads and %d connections, pipeline depth %d\n", cfg.Threads, cfg.Connections, cfg.Pipeline)
+	fmt.Fprintf(&b, "  Latency   avg %s   max %s\n", result.AvgLatency, formatLatency(float64(stats.Latency.Max())))
+	fmt.Fprintf(&b, "  Latency Distribution\n")
+	fmt.Fprintf(&b, "     50%%    %s\n", result.LatencyPercentiles.P50)
+	fmt.Fprintf(&b, "     75%%    %s\n", result.LatencyPercentiles.P75)
+	fmt.Fprintf(&b, "     90%%    %s\n", result.LatencyPercentiles.P90)
+	fmt.Fprintf(&b, "     99%%    %s\n", result.LatencyPercentiles.P99)
+	fmt.Fprintf(&b, "   99.9%%    %s\n", result.LatencyPercentiles.P999)
+	fmt.Fprintf(&b, "  99.99%%    %s\n", result.LatencyPercentiles.P9999)
+	fmt.Fprintf(&b, "  %d requests in %.2fs, %s read\n", stats.Requests, stats.Elapsed.Seconds(), formatBytes(float64(stats.BytesRead)))
+	if e := stats.Errors; e.Connect+e.Read+e.Write+e.Timeout > 0 {
+		fmt.Fprintf(&b, "  Socket errors: connect %d, read %d, write %d, timeout %d\n", e.Connect, e.Read, e.Write, e.Timeout)
+	}
+	if stats.Errors.Status > 0 {
+		fmt.Fprintf(&b, "  Non-2xx or 3xx responses: %d\n", stats.Errors.Status)
+	}
+	if t := stats.Timing; t != nil {
+		fmt.Fprintf(&b, "  Timing breakdown (%d traced samples, p50 / p99)\n", t.Samples)
+		for _, p := range t.Phases {
+			fmt.Fprintf(&b, "     %-8s %s / %s\n", p.Phase, formatLatency(float64(p.P50)), formatLatency(float64(p.P99)))
+		}
+	}
+	fmt.Fprintf(&b, "Requests/sec: %s\n", result.RequestsPerSec)
+	fmt.Fprintf(&b, "Transfer/sec: %s", result.TransferPerSec)
+
+	return b.String()
+}
+
+func slowClientSummary(slow *SlowClientResult) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Slow clients: %d, one byte every %s\n", slow.Clients, slow.Interval)
+	fmt.Fprintf(&b, "  Baseline       %s req/s, p99 %s\n", slow.Baseline.RequestsPerSec, slow.Baseline.P99)
+	fmt.Fprintf(&b, "  Under attack   %s req/s, p99 %s\n", slow.UnderAttack.RequestsPerSec, slow.UnderAttack.P99)
+	fmt.Fprintf(&b, "  Degradation    throughput %.1f%%, p99 %+.1f%%\n", slow.ThroughputDegradationPct, slow.P99DegradationPct)
+	for _, c := range slow.Connections {
+		fmt.Fprintf(&b, "  %-8s opened %d, dropped %d, survived %d, refused %d", c.Kind, c.Opened, c.Dropped, c.Survived, c.Refused)
+		if c.Dropped > 0 {
+			fmt.Fprintf(&b, ", median drop after %s", c.MedianDropAfter)
+		}
+		b.WriteString("\n")
+	}
+
+	return strings.TrimSuffix(b.String(), "\n")
+}
@@ -0,0 +1,261 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// ShutdownResult describes how a server drained when it was sent SIGTERM
+// under load. Requests count as in flight when they were written before the
+// signal and answered (or failed) after it. Refused counts the connections
+// the load generator tried to open after the signal and could not; the
+// retries of one connection count once.
+type ShutdownResult struct {
+	SignalAfter       string  `json:"signal_after"`
+	InFlight          int64   `json:"in_flight"`
+	InFlightCompleted int64   `json:"in_flight_completed"`
+	InFlightFailed    int64   `json:"in_flight_failed"`
+	DrainCompleted    int64   `json:"drain_completed"`
+	DrainFailed       int64   `json:"drain_failed"`
+	Refused           int64   `json:"refused"`
+	Exited            bool    `json:"exited"`
+	ExitMs            float64 `json:"exit_ms"`
+}
+
+// shutdownTally is one connection's share of the ShutdownResult.
+type shutdownTally struct {
+	inFlightCompleted int64
+	inFlightFailed    int64
+	drainCompleted    int64
+	drainFailed       int64
+	refused           int64
+}
+
+// outcome files a finished request by when it was written relative to the
+// signal: before it (in flight), after it (drain) or before any signal.
+func (t *shutdownTally) outcome(sent time.Time, signalled *atomic.Int64, ok bool) {
+	sig := signalled.Load()
+	switch {
+	case sig == 0:
+	case sent.UnixNano() < sig && ok:
+		t.inFlightCompleted++
+	case sent.UnixNano() < sig:
+		t.inFlightFailed++
+	case ok:
+		t.drainCompleted++
+	default:
+		t.drainFailed++
+	}
+}
+
+// runShutdownTest loads the server, sends it SIGTERM after
+// cfg.ShutdownAfter and keeps the load running until the process exits or
+// the test duration runs out.
+func runShutdownTest(cfg *Config) (*RunStats, *ShutdownResult, error) {
+	request, addr, err := buildRequest(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	probe, err := net.DialTimeout("tcp", addr, cfg.Timeout)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot connect to %s: %v", addr, err)
+	}
+	probe.Close()
+
+	results := make([]*workerStats, cfg.Connections)
+	tallies := make([]*shutdownTally, cfg.Connections)
+	var signalled atomic.Int64
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+
+	start := time.Now()
+	deadline := start.Add(cfg.Duration)
+	seconds := newTimeline(start, cfg.Duration)
+
+	for i := range results {
+		ws := &workerStats{
+			latency: NewHistogram(),
+			seconds: newSecondRecorder(seconds),
+		}
+		tally := &shutdownTally{}
+		results[i] = ws
+		tallies[i] = tally
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			shutdownConnection(cfg, addr, request, deadline, done, &signalled, ws, tally)
+			ws.seconds.flush()
+		}()
+	}
+
+	time.Sleep(cfg.ShutdownAfter)
+	signalAt := time.Now()
+	signalled.Store(signalAt.UnixNano())
+	if err := syscall.Kill(cfg.ShutdownPID, syscall.SIGTERM); err != nil {
+		close(done)
+		wg.Wait()
+		return nil, nil, fmt.Errorf("cannot signal process %d: %v", cfg.ShutdownPID, err)
+	}
+
+	exited := waitForExit(cfg.ShutdownPID, time.Until(deadline))
+	exitAfter := time.Since(signalAt)
+	close(done)
+	wg.Wait()
+
+	stats := &RunStats{
+		Elapsed: time.Since(start),
+		Latency: NewHistogram(),
+		Seconds: seconds.buckets(),
+		Heatmap: seconds.heatmap(),
+	}
+	for _, ws := range results {
+		stats.Requests += ws.requests
+		stats.BytesRead += ws.bytesRead
+		stats.Errors.add(ws.errors)
+		stats.Latency.Merge(ws.latency)
+	}
+
+	result := &ShutdownResult{
+		SignalAfter: cfg.ShutdownAfter.String(),
+		Exited:      exited,
+		ExitMs:      float64(exitAfter.Microseconds()) / 1000,
+	}
+	for _, t := range tallies {
+		result.InFlightCompleted += t.inFlightCompleted
+		result.InFlightFailed += t.inFlightFailed
+		result.DrainCompleted += t.drainCompleted
+		result.DrainFailed += t.drainFailed
+		result.Refused += t.refused
+	}
+	result.InFlight = result.InFlightCompleted + result.InFlightFailed
+
+	return stats, result, nil
+}
+
+// shutdownConnection drives one connection with one request in flight until
+// done is closed, reconnecting whenever the server closes the connection.
+func shutdownConnection(cfg *Config, addr string, request []byte, deadline time.Time, done <-chan struct{}, signalled *atomic.Int64, ws *workerStats, tally *shutdownTally) {
+	stopped := func() bool {
+		select {
+		case <-done:
+			return true
+		default:
+			return time.Now().After(deadline)
+		}
+	}
+
+	// refused is set once the connection being opened has been counted as
+	// refused, so its retries are not
+	refused := false
+	for !stopped() {
+		conn, err := net.DialTimeout("tcp", addr, cfg.Timeout)
+		if err != nil {
+			ws.fail(&ws.errors.Connect, 1)
+			if signalled.Load() != 0 && !refused {
+				tally.refused++
+				refused = true
+			}
+			time.Sleep(10 * time.Millisecond)
+			continue
+		}
+		refused = false
+
+		counter := &countingReader{r: conn}
+		reader := bufio.NewReaderSize(counter, 64*1024)
+
+		for !stopped() {
+			sent := time.Now()
+			if _, err := conn.Write(request); err != nil {
+				ws.fail(&ws.errors.Write, 1)
+				tally.outcome(sent, signalled, false)
+				break
+			}
+
+			conn.SetReadDeadline(time.Now().Add(cfg.Timeout))
+			resp, err := http.ReadResponse(reader, nil)
+			if err == nil {
+				_, err = io.Copy(io.Discard, resp.Body)
+				resp.Body.Close()
+			}
+			if err != nil {
+				if errors.Is(err, os.ErrDeadlineExceeded) {
+					ws.fail(&ws.errors.Timeout, 1)
+				} else {
+					ws.fail(&ws.errors.Read, 1)
+				}
+				tally.outcome(sent, signalled, false)
+				break
+			}
+
+			now := time.Now()
+			latency := now.Sub(sent)
+			ws.latency.Record(latency)
+			ws.seconds.record(now, latency)
+			ws.requests++
+			if resp.StatusCode > 399 {
+				ws.fail(&ws.errors.Status, 1)
+			}
+			tally.outcome(sent, signalled, resp.StatusCode <= 399)
+
+			if resp.Close {
+				break
+			}
+		}
+
+		ws.bytesRead += counter.n
+		conn.Close()
+	}
+}
+
+// waitForExit polls until the process is gone and reports whether it exited
+// within the timeout. A zombie (exited but not yet reaped by its parent)
+// counts as exited.
+func waitForExit(pid int, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for {
+		if err := syscall.Kill(pid, 0); errors.Is(err, syscall.ESRCH) || zombie(pid) {
+			return true
+		}
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func zombie(pid int) bool {
+	stat, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return false
+	}
+	// The state follows the parenthesised command name, which may itself
+	// contain spaces or parentheses.
+	fields := strings.Fields(string(stat[strings.LastIndexByte(string(stat), ')')+1:]))
+	return len(fields) > 0 && fields[0] == "Z"
+}
+
+func shutdownSummary(s *ShutdownResult) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Graceful shutdown: SIGTERM after %s\n", s.SignalAfter)
+	fmt.Fprintf(&b, "  In flight      %d (completed %d, failed %d)\n", s.InFlight, s.InFlightCompleted, s.InFlightFailed)
+	fmt.Fprintf(&b, "  While draining completed %d, failed %d, connections refused %d\n", s.DrainCompleted, s.DrainFailed, s.Refused)
+	if s.Exited {
+		fmt.Fprintf(&b, "  Exited after   %.1fms", s.ExitMs)
+	} else {
+		fmt.Fprintf(&b, "  Still running after %.1fms", s.ExitMs)
+	}
+
+	return b.String()
+}
@@ -0,0 +1,277 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Slow-client kinds. Clients are spread round-robin across them.
+const (
+	slowHeaders = "headers" // trickles an endless request header block
+	slowBody    = "body"    // sends headers, then trickles a large POST body
+	slowRead    = "read"    // pipelines requests but reads responses a byte at a time
+)
+
+var slowKinds = []string{slowHeaders, slowBody, slowRead}
+
+// SlowClientResult compares the legitimate load with and without slow
+// clients attached, and records when the server dropped them.
+type SlowClientResult struct {
+	Clients                  int               `json:"clients"`
+	Interval                 string            `json:"interval"`
+	Baseline                 PhaseSummary      `json:"baseline"`
+	UnderAttack              PhaseSummary      `json:"under_attack"`
+	ThroughputDegradationPct float64           `json:"throughput_degradation_pct"`
+	P99DegradationPct        float64           `json:"p99_degradation_pct"`
+	Connections              []SlowClientStats `json:"connections"`
+}
+
+type PhaseSummary struct {
+	RequestsPerSec string `json:"requests_per_sec"`
+	P99            string `json:"p99"`
+	Errors         int64  `json:"errors"`
+}
+
+// SlowClientStats describes one kind of slow client. A connection counts as
+// dropped when the server closed or reset it before the test ended, and as
+// survived when it was still open at the end.
+type SlowClientStats struct {
+	Kind            string `json:"kind"`
+	Opened          int64  `json:"opened"`
+	Refused         int64  `json:"refused"`
+	Dropped         int64  `json:"dropped"`
+	Survived        int64  `json:"survived"`
+	FirstDropAfter  string `json:"first_drop_after,omitempty"`
+	MedianDropAfter string `json:"median_drop_after,omitempty"`
+	MaxDropAfter    string `json:"max_drop_after,omitempty"`
+}
+
+type slowTally struct {
+	mu       sync.Mutex
+	opened   int64
+	refused  int64
+	survived int64
+	drops    []time.Duration
+}
+
+func newPhaseSummary(stats *RunStats) PhaseSummary {
+	return PhaseSummary{
+		RequestsPerSec: fmt.Sprintf("%.2f", float64(stats.Requests)/stats.Elapsed.Seconds()),
+		P99:            formatLatency(float64(stats.Latency.Percentile(99))),
+		Errors:         stats.Errors.total(),
+	}
+}
+
+// runSlowClientTest measures the legitimate load on its own, then again while
+// cfg.SlowClients slow connections are held open against the same server.
+// The returned stats are those of the attacked phase.
+func runSlowClientTest(cfg *Config) (*RunStats, *SlowClientResult, error) {
+	baseline, err := run(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	_, addr, err := buildRequest(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	stop := make(chan struct{})
+	tallies := make(map[string]*slowTally, len(slowKinds))
+	for _, kind := range slowKinds {
+		tallies[kind] = &slowTally{}
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < cfg.SlowClients; i++ {
+		kind := slowKinds[i%len(slowKinds)]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			runSlowClient(cfg, addr, kind, tallies[kind], stop)
+		}()
+	}
+
+	attacked, err := run(cfg)
+	close(stop)
+	wg.Wait()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result := &SlowClientResult{
+		Clients:     cfg.SlowClients,
+		Interval:    cfg.SlowInterval.String(),
+		Baseline:    newPhaseSummary(baseline),
+		UnderAttack: newPhaseSummary(attacked),
+	}
+
+	baseRPS := float64(baseline.Requests) / baseline.Elapsed.Seconds()
+	attackRPS := float64(attacked.Requests) / attacked.Elapsed.Seconds()
+	if baseRPS > 0 {
+		result.ThroughputDegradationPct = (baseRPS - attackRPS) / baseRPS * 100
+	}
+	if baseP99 := baseline.Latency.Percentile(99); baseP99 > 0 {
+		result.P99DegradationPct = float64(attacked.Latency.Percentile(99)-baseP99) / float64(baseP99) * 100
+	}
+
+	for _, kind := range slowKinds {
+		result.Connections = append(result.Connections, tallies[kind].summary(kind))
+	}
+
+	return attacked, result, nil
+}
+
+func (t *slowTally) summary(kind string) SlowClientStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	stats := SlowClientStats{
+		Kind:     kind,
+		Opened:   t.opened,
+		Refused:  t.refused,
+		Dropped:  int64(len(t.drops)),
+		Survived: t.survived,
+	}
+	if len(t.drops) > 0 {
+		sort.Slice(t.drops, func(i, j int) bool { return t.drops[i] < t.drops[j] })
+		stats.FirstDropAfter = formatLatency(float64(t.drops[0].Microseconds()))
+		stats.MedianDropAfter = formatLatency(float64(t.drops[len(t.drops)/2].Microseconds()))
+		stats.MaxDropAfter = formatLatency(float64(t.drops[len(t.drops)-1].Microseconds()))
+	}
+	return stats
+}
+
+// runSlowClient keeps one slow connection open until stop is closed,
+// reconnecting whenever the server drops it.
+func runSlowClient(cfg *Config, addr, kind string, tally *slowTally, stop <-chan struct{}) {
+	u, _ := url.Parse(cfg.URL)
+
+	for {
+		select {
+		case <-stop:
+			return
+		default:
+		}
+
+		conn, err := net.DialTimeout("tcp", addr, cfg.Timeout)
+		if err != nil {
+			tally.mu.Lock()
+			tally.refused++
+			tally.mu.Unlock()
+			select {
+			case <-stop:
+				return
+			case <-time.After(cfg.SlowInterval):
+			}
+			continue
+		}
+
+		tally.mu.Lock()
+		tally.opened++
+		tally.mu.Unlock()
+
+		opened := time.Now()
+		var dropped bool
+		if kind == slowRead {
+			dropped = slowReader(conn, u, cfg.SlowInterval, stop)
+		} else {
+			dropped = slowWriter(conn, u, kind, cfg.SlowInterval, stop)
+		}
+		lifetime := time.Since(opened)
+		conn.Close()
+
+		tally.mu.Lock()
+		if dropped {
+			tally.drops = append(tally.drops, lifetime)
+		} else {
+			tally.survived++
+		}
+		tally.mu.Unlock()
+	}
+}
+
+// slowWriter trickles a request one byte per interval and reports whether
+// the server gave up on the connection before stop was closed. Any response
+// or close from the server means it stopped waiting for the rest.
+func slowWriter(conn net.Conn, u *url.URL, kind string, interval time.Duration, stop <-chan struct{}) bool {
+	var head string
+	var filler string
+	if kind == slowBody {
+		head = fmt.Sprintf("POST /users HTTP/1.1\r\nHost: %s\r\nContent-Type: application/json\r\nContent-Length: 1048576\r\n\r\n{", u.Host)
+		filler = " "
+	} else {
+		head = fmt.Sprintf("GET %s HTTP/1.1\r\nHost: %s\r\n", u.RequestURI(), u.Host)
+		filler = "X-Slow: 1\r\n"
+	}
+
+	closed := make(chan struct{})
+	go func() {
+		io.Copy(io.Discard, conn)
+		close(closed)
+	}()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for i := 0; ; i++ {
+		var b byte
+		if i < len(head) {
+			b = head[i]
+		} else {
+			b = filler[(i-len(head))%len(filler)]
+		}
+		if _, err := conn.Write([]byte{b}); err != nil {
+			return true
+		}
+
+		select {
+		case <-stop:
+			return false
+		case <-closed:
+			return true
+		case <-ticker.C:
+		}
+	}
+}
+
+// slowReader pipelines requests as fast as the server accepts them but reads
+// the responses one byte per interval, so the server's writes back up.
+func slowReader(conn net.Conn, u *url.URL, interval time.Duration, stop <-chan struct{}) bool {
+	if tcp, ok := conn.(*net.TCPConn); ok {
+		tcp.SetReadBuffer(1024)
+	}
+
+	request := []byte(fmt.Sprintf("GET %s HTTP/1.1\r\nHost: %s\r\n\r\n", u.RequestURI(), u.Host))
+	go func() {
+		for {
+			if _, err := conn.Write(request); err != nil {
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	buf := make([]byte, 1)
+	for {
+		conn.SetReadDeadline(time.Now().Add(interval))
+		if _, err := conn.Read(buf); err != nil && !errors.Is(err, os.ErrDeadlineExceeded) {
+			return true
+		}
+
+		select {
+		case <-stop:
+			return false
+		case <-ticker.C:
+		}
+	}
+}
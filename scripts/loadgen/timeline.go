@@ -0,0 +1,125 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// SecondBucket is the traffic of one second of a run.
+type SecondBucket struct {
+	Second   int   `json:"second"`
+	Requests int64 `json:"requests"`
+	Errors   int64 `json:"errors"`
+	P50      int64 `json:"p50_us"`
+	P99      int64 `json:"p99_us"`
+	Max      int64 `json:"max_us"`
+}
+
+// timeline collects one latency histogram per second of the run. Workers
+// record into a private histogram for the current second and merge it here
+// when the second rolls over, so the hot path takes no locks.
+type timeline struct {
+	mu      sync.Mutex
+	start   time.Time
+	latency []*Histogram
+	errors  []int64
+}
+
+func newTimeline(start time.Time, duration time.Duration) *timeline {
+	seconds := int((duration + time.Second - 1) / time.Second)
+	t := &timeline{
+		start:   start,
+		latency: make([]*Histogram, seconds),
+		errors:  make([]int64, seconds),
+	}
+	for i := range t.latency {
+		t.latency[i] = NewHistogram()
+	}
+	return t
+}
+
+// second maps a timestamp to its bucket. Responses drained after the
+// deadline count towards the last second.
+func (t *timeline) second(at time.Time) int {
+	s := int(at.Sub(t.start) / time.Second)
+	if s >= len(t.latency) {
+		s = len(t.latency) - 1
+	}
+	if s < 0 {
+		s = 0
+	}
+	return s
+}
+
+func (t *timeline) merge(second int, latency *Histogram, errors int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.latency[second].Merge(latency)
+	t.errors[second] += errors
+}
+
+func (t *timeline) buckets() []SecondBucket {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	buckets := make([]SecondBucket, len(t.latency))
+	for i, h := range t.latency {
+		buckets[i] = SecondBucket{
+			Second:   i + 1,
+			Requests: h.Count(),
+			Errors:   t.errors[i],
+			P50:      h.Percentile(50),
+			P99:      h.Percentile(99),
+			Max:      h.Max(),
+		}
+	}
+	return buckets
+}
+
+// truncate drops the seconds after the first n, for runs that stopped early.
+func (t *timeline) truncate(n int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if n > 0 && n < len(t.latency) {
+		t.latency = t.latency[:n]
+		t.errors = t.errors[:n]
+	}
+}
+
+// secondRecorder is a worker's view of the timeline.
+type secondRecorder struct {
+	timeline *timeline
+	second   int
+	latency  *Histogram
+	errors   int64
+}
+
+func newSecondRecorder(t *timeline) *secondRecorder {
+	return &secondRecorder{timeline: t, latency: NewHistogram()}
+}
+
+func (r *secondRecorder) roll(at time.Time) {
+	if s := r.timeline.second(at); s != r.second {
+		r.flush()
+		r.second = s
+	}
+}
+
+func (r *secondRecorder) record(at time.Time, d time.Duration) {
+	r.roll(at)
+	r.latency.Record(d)
+}
+
+func (r *secondRecorder) fail(at time.Time, n int64) {
+	r.roll(at)
+	r.errors += n
+}
+
+func (r *secondRecorder) flush() {
+	if r.latency.Count() == 0 && r.errors == 0 {
+		return
+	}
+	r.timeline.merge(r.second, r.latency, r.errors)
+	r.latency.Reset()
+	r.errors = 0
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimelineSecond(t *testing.T) {
+	start := time.Unix(1000, 0)
+	tl := newTimeline(start, 2500*time.Millisecond)
+	if got := len(tl.latency); got != 3 {
+		t.Fatalf("2.5s run: %d seconds, want 3", got)
+	}
+
+	tests := []struct {
+		at   time.Duration
+		want int
+	}{
+		{-time.Second, 0},
+		{0, 0},
+		{999 * time.Millisecond, 0},
+		{time.Second, 1},
+		{2400 * time.Millisecond, 2},
+		// Responses drained after the deadline count towards the last second
+		{10 * time.Second, 2},
+	}
+	for _, tt := range tests {
+		if got := tl.second(start.Add(tt.at)); got != tt.want {
+			t.Errorf("second(start%+v) = %d, want %d", tt.at, got, tt.want)
+		}
+	}
+}
+
+func TestSecondRecorder(t *testing.T) {
+	start := time.Unix(1000, 0)
+	tl := newTimeline(start, 3*time.Second)
+	a, b := newSecondRecorder(tl), newSecondRecorder(tl)
+
+	a.record(start.Add(100*time.Millisecond), 10*time.Microsecond)
+	a.record(start.Add(200*time.Millisecond), 30*time.Microsecond)
+	b.record(start.Add(500*time.Millisecond), 20*time.Microsecond)
+	// Rolling over to the next second merges the previous one
+	a.record(start.Add(1500*time.Millisecond), 1000*time.Microsecond)
+	a.fail(start.Add(1600*time.Millisecond), 2)
+	b.fail(start.Add(2100*time.Millisecond), 1)
+	a.flush()
+	b.flush()
+
+	want := []SecondBucket{
+		{Second: 1, Requests: 3, P50: 20, P99: 30, Max: 30},
+		{Second: 2, Requests: 1, Errors: 2, P50: 1000, P99: 1000, Max: 1000},
+		{Second: 3, Errors: 1},
+	}
+	got := tl.buckets()
+	if len(got) != len(want) {
+		t.Fatalf("buckets = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("second %d = %+v, want %+v", i+1, got[i], want[i])
+		}
+	}
+}
+
+func TestTimelineTruncate(t *testing.T) {
+	tests := []struct {
+		n    int
+		want int
+	}{
+		{0, 5},
+		{2, 2},
+		{5, 5},
+		{9, 5},
+	}
+	for _, tt := range tests {
+		tl := newTimeline(time.Unix(1000, 0), 5*time.Second)
+		tl.truncate(tt.n)
+		if got := len(tl.buckets()); got != tt.want {
+			t.Errorf("truncate(%d): %d seconds, want %d", tt.n, got, tt.want)
+		}
+	}
+}
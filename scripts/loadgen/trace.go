@@ -0,0 +1,144 @@
+package main
+
+import (
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptrace"
+	"strings"
+	"time"
+)
+
+// Request phases recorded for sampled requests, in the order they happen.
+var tracePhases = []string{"dns", "connect", "write", "ttfb", "body"}
+
+// TimingBreakdown summarizes the phases of sampled requests. Samples use a
+// fresh connection each, so DNS and connect are measured every time; ttfb is
+// the time from the request being written to the first response byte
+// (server think time plus one network trip).
+type TimingBreakdown struct {
+	Samples int64         `json:"samples"`
+	Errors  int64         `json:"errors"`
+	Phases  []PhaseTiming `json:"phases"`
+}
+
+type PhaseTiming struct {
+	Phase string  `json:"phase"`
+	Mean  float64 `json:"mean_us"`
+	P50   int64   `json:"p50_us"`
+	P90   int64   `json:"p90_us"`
+	P99   int64   `json:"p99_us"`
+}
+
+type tracer struct {
+	samples int64
+	errors  int64
+	phases  map[string]*Histogram
+}
+
+func newTracer() *tracer {
+	t := &tracer{phases: make(map[string]*Histogram, len(tracePhases))}
+	for _, phase := range tracePhases {
+		t.phases[phase] = NewHistogram()
+	}
+	return t
+}
+
+// sampleTimings issues cfg.TraceRate traced requests per second until the
+// deadline or until stop is closed, alongside the main load.
+func sampleTimings(cfg *Config, deadline time.Time, stop <-chan struct{}) *tracer {
+	t := newTracer()
+	client := &http.Client{
+		Timeout: cfg.Timeout,
+		Transport: &http.Transport{
+			DisableKeepAlives:  true,
+			DisableCompression: true,
+		},
+	}
+
+	ticker := time.NewTicker(time.Second / time.Duration(cfg.TraceRate))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return t
+		case now := <-ticker.C:
+			if !now.Before(deadline) {
+				return t
+			}
+			t.sample(cfg, client)
+		}
+	}
+}
+
+func (t *tracer) sample(cfg *Config, client *http.Client) {
+	var dnsStart, dnsDone, connectStart, connectDone, gotConn, wrote, firstByte time.Time
+
+	trace := &httptrace.ClientTrace{
+		DNSStart:             func(httptrace.DNSStartInfo) { dnsStart = time.Now() },
+		DNSDone:              func(httptrace.DNSDoneInfo) { dnsDone = time.Now() },
+		ConnectStart:         func(string, string) { connectStart = time.Now() },
+		ConnectDone:          func(string, string, error) { connectDone = time.Now() },
+		GotConn:              func(httptrace.GotConnInfo) { gotConn = time.Now() },
+		WroteRequest:         func(httptrace.WroteRequestInfo) { wrote = time.Now() },
+		GotFirstResponseByte: func() { firstByte = time.Now() },
+	}
+
+	var body io.Reader
+	if cfg.Body != "" {
+		body = strings.NewReader(cfg.Body)
+	}
+	req, err := http.NewRequest(strings.ToUpper(cfg.Method), cfg.URL, body)
+	if err != nil {
+		t.errors++
+		return
+	}
+	for _, h := range cfg.Headers {
+		name, value, _ := strings.Cut(h, ":")
+		req.Header.Set(strings.TrimSpace(name), strings.TrimSpace(value))
+	}
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		t.errors++
+		return
+	}
+	_, err = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	done := time.Now()
+	if err != nil {
+		t.errors++
+		return
+	}
+
+	t.samples++
+	if !dnsStart.IsZero() && !dnsDone.IsZero() {
+		t.phases["dns"].Record(dnsDone.Sub(dnsStart))
+	}
+	if !connectStart.IsZero() && !connectDone.IsZero() {
+		t.phases["connect"].Record(connectDone.Sub(connectStart))
+	}
+	t.phases["write"].Record(wrote.Sub(gotConn))
+	t.phases["ttfb"].Record(firstByte.Sub(wrote))
+	t.phases["body"].Record(done.Sub(firstByte))
+}
+
+func (t *tracer) breakdown() *TimingBreakdown {
+	b := &TimingBreakdown{
+		Samples: t.samples,
+		Errors:  t.errors,
+	}
+	for _, phase := range tracePhases {
+		h := t.phases[phase]
+		b.Phases = append(b.Phases, PhaseTiming{
+			Phase: phase,
+			Mean:  math.Round(h.Mean()*100) / 100,
+			P50:   h.Percentile(50),
+			P90:   h.Percentile(90),
+			P99:   h.Percentile(99),
+		})
+	}
+	return b
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// WarmupResult describes an adaptive warm-up: the load ran until throughput
+// and P99 varied by less than the allowed coefficient of variation over the
+// last StableWindow seconds, or until the duration cap.
+type WarmupResult struct {
+	Seconds        float64 `json:"seconds"`
+	Stable         bool    `json:"stable"`
+	WindowSeconds  int     `json:"window_seconds"`
+	MaxRPSCV       float64 `json:"max_rps_cv"`
+	MaxP99CV       float64 `json:"max_p99_cv"`
+	RPSCV          float64 `json:"rps_cv"`
+	P99CV          float64 `json:"p99_cv"`
+	RequestsPerSec float64 `json:"requests_per_sec"`
+	P99Us          float64 `json:"p99_us"`
+}
+
+// runWarmup loads the server until it is stable, for at least
+// cfg.StableMin and at most cfg.Duration.
+func runWarmup(cfg *Config) (*RunStats, *WarmupResult, error) {
+	result := &WarmupResult{
+		WindowSeconds: cfg.StableWindow,
+		MaxRPSCV:      cfg.StableRPSCV,
+		MaxP99CV:      cfg.StableP99CV,
+	}
+	minSeconds := int(cfg.StableMin.Seconds())
+
+	// The check runs on runUntil's watcher goroutine, so it only returns a
+	// verdict; result is filled in here once the run is over
+	stable := false
+	stats, err := runUntil(cfg, func(seconds []SecondBucket) bool {
+		if len(seconds) < cfg.StableWindow || len(seconds) < minSeconds {
+			return false
+		}
+		check := *result
+		check.measure(seconds[len(seconds)-cfg.StableWindow:])
+		stable = check.Stable
+		return stable
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result.Seconds = math.Round(stats.Elapsed.Seconds()*10) / 10
+	if len(stats.Seconds) > 0 {
+		// Report the window the run ended on. The last second is partial
+		// (cut by the early stop or the cap), so it is left out.
+		seconds := stats.Seconds[:len(stats.Seconds)-1]
+		if len(seconds) > cfg.StableWindow {
+			seconds = seconds[len(seconds)-cfg.StableWindow:]
+		}
+		if len(seconds) > 0 {
+			result.measure(seconds)
+		}
+	}
+	// The verdict is the one that ended the run: workers may still have
+	// merged into the window after it was checked
+	result.Stable = stable
+	return stats, result, nil
+}
+
+// measure sets the throughput and P99 of window, their variation and
+// whether they are within the allowed variation.
+func (w *WarmupResult) measure(window []SecondBucket) {
+	w.RPSCV, w.RequestsPerSec = variation(window, func(s SecondBucket) float64 { return float64(s.Requests) })
+	w.P99CV, w.P99Us = variation(window, func(s SecondBucket) float64 { return float64(s.P99) })
+	w.Stable = w.RequestsPerSec > 0 && w.RPSCV <= w.MaxRPSCV && w.P99CV <= w.MaxP99CV
+}
+
+// variation returns the coefficient of variation (standard deviation over
+// mean) and the mean of one value of each second.
+func variation(seconds []SecondBucket, value func(SecondBucket) float64) (cv, mean float64) {
+	for _, s := range seconds {
+		mean += value(s)
+	}
+	mean /= float64(len(seconds))
+	if mean == 0 {
+		return 0, 0
+	}
+
+	var squares float64
+	for _, s := range seconds {
+		d := value(s) - mean
+		squares += d * d
+	}
+	return math.Sqrt(squares/float64(len(seconds))) / mean, mean
+}
+
+func warmupSummary(w *WarmupResult) string {
+	var b strings.Builder
+
+	verdict := "stable"
+	if !w.Stable {
+		verdict = "not stable, stopped at the cap"
+	}
+	fmt.Fprintf(&b, "Warm-up: %.1fs (%s)\n", w.Seconds, verdict)
+	fmt.Fprintf(&b, "  Last %ds     %.0f req/s (CV %.3f, max %.3f), p99 %s (CV %.3f, max %.3f)",
+		w.WindowSeconds, w.RequestsPerSec, w.RPSCV, w.MaxRPSCV, formatLatency(w.P99Us), w.P99CV, w.MaxP99CV)
+
+	return b.String()
+}
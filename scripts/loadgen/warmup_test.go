@@ -0,0 +1,58 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func requests(counts ...int64) []SecondBucket {
+	seconds := make([]SecondBucket, len(counts))
+	for i, c := range counts {
+		seconds[i] = SecondBucket{Second: i + 1, Requests: c, P99: 1000}
+	}
+	return seconds
+}
+
+func TestVariation(t *testing.T) {
+	tests := []struct {
+		name    string
+		seconds []SecondBucket
+		cv      float64
+		mean    float64
+	}{
+		{"flat", requests(100, 100, 100), 0, 100},
+		{"zero", requests(0, 0, 0), 0, 0},
+		{"noisy", requests(90, 110, 90, 110), 0.1, 100},
+		{"ramp", requests(50, 100, 150), math.Sqrt(5000.0/3) / 100, 100},
+	}
+	for _, tt := range tests {
+		cv, mean := variation(tt.seconds, func(s SecondBucket) float64 { return float64(s.Requests) })
+		if math.Abs(cv-tt.cv) > 1e-9 || mean != tt.mean {
+			t.Errorf("%s: variation = (%v, %v), want (%v, %v)", tt.name, cv, mean, tt.cv, tt.mean)
+		}
+	}
+}
+
+func TestWarmupMeasure(t *testing.T) {
+	spiky := requests(1000, 1000, 1000)
+	spiky[1].P99 = 5000
+
+	tests := []struct {
+		name   string
+		window []SecondBucket
+		stable bool
+	}{
+		{"flat", requests(1000, 1000, 1000), true},
+		{"within the throughput limit", requests(960, 1000, 1040), true},
+		{"noisy throughput", requests(500, 1000, 1500), false},
+		{"no traffic", requests(0, 0, 0), false},
+		{"P99 spike", spiky, false},
+	}
+	for _, tt := range tests {
+		w := &WarmupResult{MaxRPSCV: 0.05, MaxP99CV: 0.2}
+		w.measure(tt.window)
+		if w.Stable != tt.stable {
+			t.Errorf("%s: stable = %v (requests/sec CV %.3f, P99 CV %.3f), want %v", tt.name, w.Stable, w.RPSCV, w.P99CV, tt.stable)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+-- Reports what `wrk --latency` leaves out: the 99.9% and 99.99% tail, the
+-- maximum and a percentile spectrum (the same list loadgen stores in
+-- percentile_spectrum). When WRK_HGRM_FILE is set, the distribution is also
+-- written there in HdrHistogram's percentile distribution format.
+--
+-- Loaded by scripts/benchmark.sh for every wrk run; request scripts such as
+-- the POST body are appended to it.
+
+local spectrum = {0, 10, 20, 30, 40, 50, 60, 70, 75, 80, 90, 95, 97.5, 99, 99.5, 99.9, 99.95, 99.99, 99.999, 100}
+
+local function format_latency(us)
+   if us >= 1e6 then
+      return string.format("%.2fs", us / 1e6)
+   elseif us >= 1e3 then
+      return string.format("%.2fms", us / 1e3)
+   end
+   return string.format("%.2fus", us)
+end
+
+local function at(latency, p)
+   if p >= 100 then
+      return latency.max
+   elseif p <= 0 then
+      return latency.min
+   end
+   return latency:percentile(p)
+end
+
+-- Same steps as loadgen's writeDistribution: five per halving of the tail.
+local function write_distribution(path, latency, count)
+   local f = io.open(path, "w")
+   if not f then
+      io.stderr:write("percentiles.lua: cannot write " .. path .. "\n")
+      return
+   end
+
+   f:write(string.format("%12s %14s %10s %14s\n\n", "Value", "Percentile", "TotalCount", "1/(1-Percentile)"))
+   local level = 0
+   while true do
+      if level >= 100 or math.ceil(level / 100 * count) >= count then
+         f:write(string.format("%12.3f %1.12f %10d\n", latency.max / 1000, 1.0, count))
+         break
+      end
+      f:write(string.format("%12.3f %1.12f %10d %14.2f\n",
+         at(latency, level) / 1000, level / 100, math.max(1, math.ceil(level / 100 * count)), 100 / (100 - level)))
+
+      local halvings = math.floor(math.log(100 / (100 - level)) / math.log(2)) + 1
+      level = level + 100 / 5 / 2 ^ halvings
+   end
+   f:write(string.format("#[Mean    = %12.3f, StdDeviation   = %12.3f]\n", latency.mean / 1000, latency.stdev / 1000))
+   f:write(string.format("#[Max     = %12.3f, Total count    = %12d]\n", latency.max / 1000, count))
+   f:close()
+end
+
+function done(summary, latency, requests)
+   io.write("  Tail Latency\n")
+   io.write(string.format("   99.9%%    %s\n", format_latency(at(latency, 99.9))))
+   io.write(string.format("  99.99%%    %s\n", format_latency(at(latency, 99.99))))
+   io.write(string.format("     max    %s\n", format_latency(latency.max)))
+
+   local points = {}
+   for _, p in ipairs(spectrum) do
+      table.insert(points, string.format("%g=%d", p, at(latency, p)))
+   end
+   io.write("  Percentile spectrum (us): " .. table.concat(points, " ") .. "\n")
+
+   local path = os.getenv("WRK_HGRM_FILE")
+   if path and path ~= "" then
+      write_distribution(path, latency, summary.requests)
+   end
+end
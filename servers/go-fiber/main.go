@@ -3,7 +3,10 @@ package main
 import (
 	"fmt"
 	"log"
+	"os"
+	"os/signal"
 	"strconv"
+	"syscall"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -96,5 +99,20 @@ func main() {
 	})
 
 	log.Println("Go Fiber server starting on :8080")
-	log.Fatal(app.Listen(":8080"))
+	go func() {
+		if err := app.Listen(":8080"); err != nil {
+			log.Fatal(err)
+		}
+	}()
+
+	// Graceful shutdown: stop accepting connections on SIGTERM and let
+	// in-flight requests finish before exiting
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
+	<-stop
+
+	log.Println("Shutting down, draining in-flight requests...")
+	if err := app.ShutdownWithTimeout(10 * time.Second); err != nil {
+		log.Printf("Shutdown: %v", err)
+	}
 }
@@ -0,0 +1,73 @@
+//go:build benchmetrics
+
+package main
+
+// Built only with -tags benchmetrics (scripts/benchmark.sh memory sampling
+// and --gc-telemetry). Serves every runtime/metrics sample as JSON on a side
+// port so the orchestrator can track the heap and GC while the server is
+// under load, without touching the benchmarked routes.
+//
+// templates/go-template/metrics.go is the original of every Go server's copy:
+// scripts/benchmark.sh copies it in before a tagged build, and
+// make check-server-files fails when a copy has diverged.
+
+import (
+	"encoding/json"
+	"log"
+	"math"
+	"net/http"
+	"os"
+	"runtime/metrics"
+)
+
+type metricsHistogram struct {
+	Counts  []uint64  `json:"counts"`
+	Buckets []float64 `json:"buckets"`
+}
+
+func init() {
+	addr := ":6061"
+	if port := os.Getenv("METRICS_PORT"); port != "" {
+		addr = ":" + port
+	}
+
+	samples := make([]metrics.Sample, 0)
+	for _, d := range metrics.All() {
+		samples = append(samples, metrics.Sample{Name: d.Name})
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/metrics", func(w http.ResponseWriter, r *http.Request) {
+		read := make([]metrics.Sample, len(samples))
+		copy(read, samples)
+		metrics.Read(read)
+
+		values := make(map[string]any, len(read))
+		for _, s := range read {
+			switch s.Value.Kind() {
+			case metrics.KindUint64:
+				values[s.Name] = s.Value.Uint64()
+			case metrics.KindFloat64:
+				values[s.Name] = s.Value.Float64()
+			case metrics.KindFloat64Histogram:
+				h := s.Value.Float64Histogram()
+				// JSON has no infinities; the open-ended buckets are clamped
+				buckets := make([]float64, len(h.Buckets))
+				for i, b := range h.Buckets {
+					buckets[i] = math.Max(-math.MaxFloat64, math.Min(math.MaxFloat64, b))
+				}
+				values[s.Name] = metricsHistogram{Counts: h.Counts, Buckets: buckets}
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(values)
+	})
+
+	go func() {
+		log.Printf("runtime metrics listening on %s", addr)
+		if err := http.ListenAndServe(addr, mux); err != nil {
+			log.Printf("runtime metrics: %v", err)
+		}
+	}()
+}
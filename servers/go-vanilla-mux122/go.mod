@@ -0,0 +1,3 @@
+module go-vanilla-mux122
+
+go 1.22
@@ -0,0 +1,132 @@
+package main
+
+// go-vanilla routed with the method and wildcard patterns of Go 1.22's
+// ServeMux instead of hand-parsed paths and method checks in every handler.
+// The handlers are otherwise the same, so the difference to go-vanilla on
+// the benchmarked routes is the cost of the pattern router. Off those
+// routes, unknown paths get a 404 instead of falling through to /, and HEAD
+// is answered like GET instead of with a 405.
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+type Response struct {
+	Message   string    `json:"message"`
+	Timestamp time.Time `json:"timestamp"`
+	Data      any       `json:"data,omitempty"`
+}
+
+type User struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func main() {
+	mux := http.NewServeMux()
+
+	// Simple GET endpoint. {$} matches / only; other methods get a 405 from
+	// the mux
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, r *http.Request) {
+		response := Response{
+			Message:   "Hello, World!",
+			Timestamp: time.Now(),
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	})
+
+	// GET endpoint with path parameter
+	mux.HandleFunc("GET /user/{id}", func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		user := User{
+			ID:   id,
+			Name: fmt.Sprintf("User %d", id),
+		}
+
+		response := Response{
+			Message:   "User retrieved successfully",
+			Timestamp: time.Now(),
+			Data:      user,
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	})
+
+	// POST endpoint
+	mux.HandleFunc("POST /users", func(w http.ResponseWriter, r *http.Request) {
+		var user User
+		if err := json.NewDecoder(r.Body).Decode(&user); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		// Simulate processing
+		user.ID = int(time.Now().Unix() % 10000)
+
+		response := Response{
+			Message:   "User created successfully",
+			Timestamp: time.Now(),
+			Data:      user,
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(response)
+	})
+
+	// Health check endpoint
+	mux.HandleFunc("GET /health", func(w http.ResponseWriter, r *http.Request) {
+		response := Response{
+			Message:   "OK",
+			Timestamp: time.Now(),
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	})
+
+	server := &http.Server{
+		Addr:         ":8080",
+		Handler:      mux,
+		ReadTimeout:  10 * time.Second,
+		WriteTimeout: 10 * time.Second,
+	}
+
+	log.Println("Go vanilla net/http server (Go 1.22 routing) starting on :8080")
+	go func() {
+		if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal(err)
+		}
+	}()
+
+	// Graceful shutdown: stop accepting connections on SIGTERM and let
+	// in-flight requests finish before exiting
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
+	<-stop
+
+	log.Println("Shutting down, draining in-flight requests...")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		log.Printf("Shutdown: %v", err)
+	}
+}
@@ -0,0 +1,3 @@
+module go-vanilla-optimized
+
+go 1.21
@@ -0,0 +1,280 @@
+package main
+
+// go-vanilla with the handler code tuned for throughput: the same net/http
+// server, routes and JSON, but responses are appended to pooled buffers by
+// hand instead of going through json.NewEncoder with fresh structs, and the
+// Content-Type header value is shared. The GET handlers do not allocate; the
+// difference to go-vanilla is the cost of idiomatic handler code.
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+	"unicode/utf8"
+)
+
+// Response and User are the contract shared with the other servers. The
+// encoders below write exactly what encoding/json writes for them.
+type Response struct {
+	Message   string    `json:"message"`
+	Timestamp time.Time `json:"timestamp"`
+	Data      any       `json:"data,omitempty"`
+}
+
+type User struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// The start of every response body, up to the timestamp value
+const (
+	helloPrefix     = `{"message":"Hello, World!","timestamp":"`
+	healthPrefix    = `{"message":"OK","timestamp":"`
+	retrievedPrefix = `{"message":"User retrieved successfully","timestamp":"`
+	createdPrefix   = `{"message":"User created successfully","timestamp":"`
+)
+
+// jsonContentType is assigned to the header map directly, which skips
+// canonicalizing the key and allocating the value slice
+var jsonContentType = []string{"application/json"}
+
+// bufferPool holds response buffers. Most responses fit in the initial
+// capacity; larger ones grow the buffer for its next use, up to
+// maxPooledBuffer.
+var bufferPool = sync.Pool{
+	New: func() any {
+		b := make([]byte, 0, 512)
+		return &b
+	},
+}
+
+// maxPooledBuffer is the largest buffer returned to bufferPool, so one
+// large response does not stay pinned in the pool.
+const maxPooledBuffer = 64 << 10
+
+// maxBodyBytes limits the size of request bodies.
+const maxBodyBytes = 1 << 20
+
+func main() {
+	mux := http.NewServeMux()
+
+	// Simple GET endpoint
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		buf := bufferPool.Get().(*[]byte)
+		b := appendResponse((*buf)[:0], helloPrefix, time.Now())
+		writeJSON(w, http.StatusOK, b)
+		putBuffer(buf, b)
+	})
+
+	// GET endpoint with path parameter
+	mux.HandleFunc("/user/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		// Extract ID from path
+		idStr := r.URL.Path[len("/user/"):]
+		if idStr == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		id, err := strconv.Atoi(idStr)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		buf := bufferPool.Get().(*[]byte)
+		b := appendTimestamp(append((*buf)[:0], retrievedPrefix...), time.Now())
+		b = append(b, `","data":{"id":`...)
+		b = strconv.AppendInt(b, int64(id), 10)
+		b = append(b, `,"name":"User `...)
+		b = strconv.AppendInt(b, int64(id), 10)
+		b = append(b, "\"}}\n"...)
+		writeJSON(w, http.StatusOK, b)
+		putBuffer(buf, b)
+	})
+
+	// POST endpoint
+	mux.HandleFunc("/users", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		var user User
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodyBytes)).Decode(&user); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		// Simulate processing
+		now := time.Now()
+		user.ID = int(now.Unix() % 10000)
+
+		buf := bufferPool.Get().(*[]byte)
+		b := appendUserResponse((*buf)[:0], createdPrefix, now, user)
+		writeJSON(w, http.StatusCreated, b)
+		putBuffer(buf, b)
+	})
+
+	// Health check endpoint
+	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		buf := bufferPool.Get().(*[]byte)
+		b := appendResponse((*buf)[:0], healthPrefix, time.Now())
+		writeJSON(w, http.StatusOK, b)
+		putBuffer(buf, b)
+	})
+
+	server := &http.Server{
+		Addr:         ":8080",
+		Handler:      mux,
+		ReadTimeout:  10 * time.Second,
+		WriteTimeout: 10 * time.Second,
+	}
+
+	log.Println("Go vanilla (optimized) net/http server starting on :8080")
+	go func() {
+		if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal(err)
+		}
+	}()
+
+	// Graceful shutdown: stop accepting connections on SIGTERM and let
+	// in-flight requests finish before exiting
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
+	<-stop
+
+	log.Println("Shutting down, draining in-flight requests...")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		log.Printf("Shutdown: %v", err)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, body []byte) {
+	w.Header()["Content-Type"] = jsonContentType
+	if status != http.StatusOK {
+		w.WriteHeader(status)
+	}
+	w.Write(body)
+}
+
+// appendResponse appends a Response without data.
+func appendResponse(b []byte, prefix string, now time.Time) []byte {
+	b = appendTimestamp(append(b, prefix...), now)
+	return append(b, "\"}\n"...)
+}
+
+// appendUserResponse appends a Response with user as its data.
+func appendUserResponse(b []byte, prefix string, now time.Time, user User) []byte {
+	b = appendTimestamp(append(b, prefix...), now)
+	b = append(b, `","data":{"id":`...)
+	b = strconv.AppendInt(b, int64(user.ID), 10)
+	b = append(b, `,"name":`...)
+	b = appendString(b, user.Name)
+	return append(b, "}}\n"...)
+}
+
+// appendTimestamp appends now the way time.Time marshals to JSON.
+func appendTimestamp(b []byte, now time.Time) []byte {
+	return now.AppendFormat(b, time.RFC3339Nano)
+}
+
+// putBuffer returns buf to bufferPool with b, the grown buffer, as its
+// contents, unless b has grown past maxPooledBuffer.
+func putBuffer(buf *[]byte, b []byte) {
+	if cap(b) > maxPooledBuffer {
+		return
+	}
+	*buf = b
+	bufferPool.Put(buf)
+}
+
+const hex = "0123456789abcdef"
+
+// invalidUTF8 is what encoding/json writes for a byte of invalid UTF-8: the
+// escape \ufffd, or the raw U+FFFD where encoding/json is built on
+// encoding/json/v2 (GOEXPERIMENT=jsonv2).
+var invalidUTF8 = func() string {
+	b, _ := json.Marshal("\xff")
+	return string(b[1 : len(b)-1])
+}()
+
+// appendString appends s as a JSON string the way encoding/json does:
+// quotes, backslashes, control characters, the HTML characters <, > and &
+// and the line separators U+2028 and U+2029 are escaped, and invalid UTF-8
+// is replaced with invalidUTF8.
+func appendString(b []byte, s string) []byte {
+	b = append(b, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		if c := s[i]; c < utf8.RuneSelf {
+			if c >= 0x20 && c != '"' && c != '\\' && c != '<' && c != '>' && c != '&' {
+				i++
+				continue
+			}
+			b = append(b, s[start:i]...)
+			switch c {
+			case '"', '\\':
+				b = append(b, '\\', c)
+			case '\n':
+				b = append(b, '\\', 'n')
+			case '\r':
+				b = append(b, '\\', 'r')
+			case '\t':
+				b = append(b, '\\', 't')
+			case '\b':
+				b = append(b, '\\', 'b')
+			case '\f':
+				b = append(b, '\\', 'f')
+			default:
+				b = append(b, '\\', 'u', '0', '0', hex[c>>4], hex[c&0xf])
+			}
+			i++
+			start = i
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			b = append(b, s[start:i]...)
+			b = append(b, invalidUTF8...)
+			i += size
+			start = i
+			continue
+		}
+		if r == '\u2028' || r == '\u2029' {
+			b = append(b, s[start:i]...)
+			b = append(b, '\\', 'u', '2', '0', '2', hex[r&0xf])
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	b = append(b, s[start:]...)
+	return append(b, '"')
+}
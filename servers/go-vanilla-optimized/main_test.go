@@ -0,0 +1,80 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestAppendString(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+	}{
+		{"empty", ""},
+		{"plain", "Test User"},
+		{"quotes and backslashes", `say "hi" \ bye`},
+		{"control characters", "a\nb\rc\td\be\ff\x00g\x1fh\x7f"},
+		{"html", "<script>&amp;</script>"},
+		{"line separators", "a\u2028b\u2029c"},
+		{"multi-byte", "héllo wörld 日本 🚀"},
+		{"invalid utf-8", "a\xffb\xc3(c\xed\xa0\x80d"},
+		{"truncated rune", "abc\xe6\x97"},
+		{"replacement character", "a\ufffdb"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want, err := json.Marshal(tt.s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := appendString(nil, tt.s); string(got) != string(want) {
+				t.Errorf("appendString(%q) = %s, want %s", tt.s, got, want)
+			}
+		})
+	}
+}
+
+func TestAppendResponse(t *testing.T) {
+	now := time.Date(2024, 3, 9, 14, 5, 7, 120000, time.FixedZone("", -7*3600))
+	tests := []struct {
+		prefix  string
+		message string
+	}{
+		{helloPrefix, "Hello, World!"},
+		{healthPrefix, "OK"},
+	}
+	for _, tt := range tests {
+		want := marshal(t, Response{Message: tt.message, Timestamp: now})
+		if got := appendResponse(nil, tt.prefix, now); string(got) != want {
+			t.Errorf("appendResponse(%q) = %s, want %s", tt.message, got, want)
+		}
+	}
+}
+
+func TestAppendUserResponse(t *testing.T) {
+	now := time.Date(2024, 3, 9, 14, 5, 7, 0, time.UTC)
+	tests := []User{
+		{ID: 0, Name: ""},
+		{ID: 123, Name: "Test User"},
+		{ID: -5, Name: `"quoted" <b>&</b>`},
+		{ID: 9999, Name: "tab\there \x01"},
+		{ID: 42, Name: "bad \xff utf-8"},
+	}
+	for _, user := range tests {
+		want := marshal(t, Response{Message: "User created successfully", Timestamp: now, Data: user})
+		if got := appendUserResponse(nil, createdPrefix, now, user); string(got) != want {
+			t.Errorf("appendUserResponse(%+v) = %s, want %s", user, got, want)
+		}
+	}
+}
+
+// marshal encodes v the way go-vanilla does, with json.NewEncoder.
+func marshal(t *testing.T, v any) string {
+	t.Helper()
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b) + "\n"
+}
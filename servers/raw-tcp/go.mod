@@ -0,0 +1,3 @@
+module raw-tcp
+
+go 1.21
@@ -0,0 +1,325 @@
+package main
+
+// The baseline of the benchmark: the four routes served straight from net,
+// without an HTTP parser. Requests are only split into request line, the
+// Content-Length, Transfer-Encoding and Connection headers and body, and
+// every response is built ahead and written as precomputed bytes, so the
+// throughput of this server is the upper bound of what an HTTP server can
+// reach on the machine. The responses are rebuilt once a second, which keeps
+// their Date header and timestamps current.
+//
+// The default build uses net only. Like the other Go servers, builds with
+// -tags benchprofile or benchmetrics add profile.go and metrics.go, whose
+// side listeners bring in net/http, pprof and runtime/metrics; they serve
+// other ports and leave the request path above untouched.
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"os/signal"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+type Response struct {
+	Message   string    `json:"message"`
+	Timestamp time.Time `json:"timestamp"`
+	Data      any       `json:"data,omitempty"`
+}
+
+type User struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// responses are the precomputed responses of one second.
+type responses struct {
+	// now is the time the responses were built at
+	now        time.Time
+	root       []byte
+	health     []byte
+	createUser []byte
+	// The /user/{id} response of the ID the benchmark requests; other IDs
+	// are built per request
+	benchmarkUser []byte
+
+	badRequest       []byte
+	methodNotAllowed []byte
+	lengthRequired   []byte
+}
+
+// dateFormat is the format of the Date header, http.TimeFormat.
+const dateFormat = "Mon, 02 Jan 2006 15:04:05 GMT"
+
+// current holds the responses of the current second.
+var current atomic.Pointer[responses]
+
+// shuttingDown makes connections close after their current request.
+var shuttingDown atomic.Bool
+
+func main() {
+	current.Store(buildResponses(time.Now()))
+	go func() {
+		for now := range time.Tick(time.Second) {
+			current.Store(buildResponses(now))
+		}
+	}()
+
+	listener, err := net.Listen("tcp", ":8080")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	log.Println("Raw TCP server starting on :8080")
+	var connections sync.WaitGroup
+	var open sync.Map
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				if !errors.Is(err, net.ErrClosed) {
+					log.Printf("Accept: %v", err)
+				}
+				return
+			}
+			connections.Add(1)
+			open.Store(conn, struct{}{})
+			go func() {
+				defer connections.Done()
+				defer open.Delete(conn)
+				serve(conn)
+			}()
+		}
+	}()
+
+	// Graceful shutdown: stop accepting connections on SIGTERM, wake idle
+	// connections and let in-flight requests finish before exiting
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
+	<-stop
+
+	log.Println("Shutting down, draining in-flight requests...")
+	shuttingDown.Store(true)
+	listener.Close()
+	open.Range(func(conn, _ any) bool {
+		conn.(net.Conn).SetReadDeadline(time.Now())
+		return true
+	})
+
+	drained := make(chan struct{})
+	go func() {
+		connections.Wait()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+	case <-time.After(10 * time.Second):
+		log.Printf("Shutdown: timed out waiting for connections")
+	}
+}
+
+// serve answers the requests of one connection. Responses to pipelined
+// requests are buffered and written together once no more requests are
+// waiting.
+func serve(conn net.Conn) {
+	defer conn.Close()
+
+	reader := bufio.NewReaderSize(conn, 4096)
+	writer := bufio.NewWriterSize(conn, 4096)
+	for {
+		// Shutdown wakes idle connections with an expired deadline; a
+		// connection that re-arms its deadline after that sees the flag
+		conn.SetReadDeadline(time.Now().Add(10 * time.Second))
+		if shuttingDown.Load() {
+			return
+		}
+		r := current.Load()
+		response, keepAlive, err := readRequest(reader, r)
+		if err != nil {
+			if !errors.Is(err, io.EOF) && !errors.Is(err, os.ErrDeadlineExceeded) {
+				writer.Write(r.badRequest)
+				writer.Flush()
+			}
+			return
+		}
+
+		writer.Write(response)
+		closing := !keepAlive || shuttingDown.Load()
+		if reader.Buffered() == 0 || closing {
+			if err := writer.Flush(); err != nil {
+				return
+			}
+		}
+		if closing {
+			return
+		}
+	}
+}
+
+// readRequest reads one request and returns the response to it, and whether
+// the connection stays open after it: by default for HTTP/1.1 and with
+// Connection: keep-alive for HTTP/1.0. Only the request line and the
+// Content-Length, Transfer-Encoding and Connection headers are looked at;
+// the body is skipped. Chunked bodies are not supported: they are answered
+// with 411 and the connection is closed.
+func readRequest(reader *bufio.Reader, r *responses) ([]byte, bool, error) {
+	line, err := reader.ReadSlice('\n')
+	if err != nil {
+		return nil, false, err
+	}
+	method, rest, ok := bytes.Cut(line, []byte(" "))
+	if !ok {
+		return nil, false, errors.New("malformed request line")
+	}
+	target, version, ok := bytes.Cut(rest, []byte(" "))
+	if !ok {
+		return nil, false, errors.New("malformed request line")
+	}
+	path, _, _ := bytes.Cut(target, []byte("?"))
+	response := route(r, method, path)
+
+	contentLength := 0
+	keepAlive := string(bytes.TrimSpace(version)) != "HTTP/1.0"
+	chunked := false
+	for {
+		header, err := reader.ReadSlice('\n')
+		if err != nil {
+			return nil, false, err
+		}
+		if len(bytes.TrimRight(header, "\r\n")) == 0 {
+			break
+		}
+		name, value, ok := bytes.Cut(header, []byte(":"))
+		if !ok {
+			continue
+		}
+		value = bytes.TrimSpace(value)
+		switch {
+		case bytes.EqualFold(name, []byte("Content-Length")):
+			contentLength, err = strconv.Atoi(string(value))
+			if err != nil || contentLength < 0 {
+				return nil, false, errors.New("malformed Content-Length")
+			}
+		case bytes.EqualFold(name, []byte("Transfer-Encoding")):
+			chunked = true
+		case bytes.EqualFold(name, []byte("Connection")):
+			if bytes.EqualFold(value, []byte("close")) {
+				keepAlive = false
+			} else if bytes.EqualFold(value, []byte("keep-alive")) {
+				keepAlive = true
+			}
+		}
+	}
+
+	if chunked {
+		return r.lengthRequired, false, nil
+	}
+	if _, err := reader.Discard(contentLength); err != nil {
+		return nil, false, err
+	}
+	return response, keepAlive, nil
+}
+
+// route picks the response the way go-vanilla's ServeMux does: /health and
+// /users exactly, /user/ by prefix and everything else falls through to /.
+func route(r *responses, method, path []byte) []byte {
+	get := string(method) == "GET"
+	switch {
+	case string(path) == "/health":
+		if !get {
+			return r.methodNotAllowed
+		}
+		return r.health
+	case string(path) == "/users":
+		if string(method) != "POST" {
+			return r.methodNotAllowed
+		}
+		return r.createUser
+	case bytes.HasPrefix(path, []byte("/user/")):
+		if !get {
+			return r.methodNotAllowed
+		}
+		return userResponse(r, path[len("/user/"):])
+	default:
+		if !get {
+			return r.methodNotAllowed
+		}
+		return r.root
+	}
+}
+
+// benchmarkUserID is the ID of the benchmark's GET /user/{id} requests.
+const benchmarkUserID = 123
+
+func userResponse(r *responses, idStr []byte) []byte {
+	id, err := strconv.Atoi(string(idStr))
+	if err != nil {
+		return r.badRequest
+	}
+	if id == benchmarkUserID {
+		return r.benchmarkUser
+	}
+	return buildUserResponse(id, r.now)
+}
+
+func buildResponses(now time.Time) *responses {
+	return &responses{
+		now:    now,
+		root:   jsonResponse("200 OK", now, Response{Message: "Hello, World!", Timestamp: now}),
+		health: jsonResponse("200 OK", now, Response{Message: "OK", Timestamp: now}),
+		// Answered without decoding the payload, as for the benchmark's
+		// {"name":"Test User"}
+		createUser: jsonResponse("201 Created", now, Response{
+			Message:   "User created successfully",
+			Timestamp: now,
+			Data:      User{ID: int(now.Unix() % 10000), Name: "Test User"},
+		}),
+		benchmarkUser: buildUserResponse(benchmarkUserID, now),
+
+		badRequest:       emptyResponse("400 Bad Request", now, ""),
+		methodNotAllowed: emptyResponse("405 Method Not Allowed", now, ""),
+		lengthRequired:   emptyResponse("411 Length Required", now, "Connection: close\r\n"),
+	}
+}
+
+func buildUserResponse(id int, now time.Time) []byte {
+	return jsonResponse("200 OK", now, Response{
+		Message:   "User retrieved successfully",
+		Timestamp: now,
+		Data:      User{ID: id, Name: fmt.Sprintf("User %d", id)},
+	})
+}
+
+// jsonResponse builds the complete HTTP response with body as JSON, dated
+// now.
+func jsonResponse(status string, now time.Time, body any) []byte {
+	data, err := json.Marshal(body)
+	if err != nil {
+		log.Fatal(err)
+	}
+	data = append(data, '\n')
+	return append([]byte("HTTP/1.1 "+status+"\r\n"+
+		"Content-Type: application/json\r\n"+
+		"Date: "+now.UTC().Format(dateFormat)+"\r\n"+
+		"Content-Length: "+strconv.Itoa(len(data))+"\r\n"+
+		"\r\n"), data...)
+}
+
+// emptyResponse builds a response without a body, dated now, with the extra
+// header lines in headers.
+func emptyResponse(status string, now time.Time, headers string) []byte {
+	return []byte("HTTP/1.1 " + status + "\r\n" +
+		"Date: " + now.UTC().Format(dateFormat) + "\r\n" +
+		headers +
+		"Content-Length: 0\r\n\r\n")
+}
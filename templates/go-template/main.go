@@ -1,11 +1,16 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
 	"strconv"
+	"syscall"
 	"time"
 )
 
@@ -61,7 +66,28 @@ func main() {
 	}
 
 	log.Println("TEMPLATE: [Your Framework Name] server starting on :8080")
-	log.Fatal(server.ListenAndServe())
+	go func() {
+		if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal(err)
+		}
+	}()
+
+	// REQUIRED: Graceful shutdown - on SIGTERM, stop accepting connections
+	// and let in-flight requests finish before exiting
+	// TEMPLATE: Use your framework's shutdown method
+	// Example for Fiber: app.ShutdownWithTimeout(10 * time.Second)
+	// Example for Echo: e.Shutdown(ctx)
+	// Gin and Chi run on http.Server, so server.Shutdown(ctx) works as is
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
+	<-stop
+
+	log.Println("Shutting down, draining in-flight requests...")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		log.Printf("Shutdown: %v", err)
+	}
 }
 
 // REQUIRED ENDPOINT 1: Root endpoint
@@ -177,7 +203,8 @@ func handleUserPost(w http.ResponseWriter, r *http.Request) {
 // 3. Ensure all endpoints return the same JSON structure
 // 4. Server must listen on port 8080
 // 5. Add error handling appropriate for your framework
-// 6. Test all endpoints before submitting
+// 6. Shut down gracefully on SIGTERM (finish in-flight requests, then exit)
+// 7. Test all endpoints before submitting
 //
 // Required endpoints:
 // - GET /                -> Hello World
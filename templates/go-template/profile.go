@@ -0,0 +1,44 @@
+//go:build benchprofile
+
+package main
+
+// Built only with -tags benchprofile (scripts/benchmark.sh --profile). Serves
+// net/http/pprof on a side port so the orchestrator can capture profiles
+// while the server is under load, without touching the benchmarked routes.
+//
+// templates/go-template/profile.go is the original of every Go server's copy:
+// scripts/benchmark.sh copies it in before a tagged build, and
+// make check-server-files fails when a copy has diverged.
+
+import (
+	"log"
+	"net/http"
+	"net/http/pprof"
+	"os"
+	"runtime"
+)
+
+func init() {
+	// Mutex and block profiles are empty unless sampling is switched on
+	runtime.SetMutexProfileFraction(5)
+	runtime.SetBlockProfileRate(10000)
+
+	addr := ":6060"
+	if port := os.Getenv("PPROF_PORT"); port != "" {
+		addr = ":" + port
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+
+	go func() {
+		log.Printf("pprof listening on %s", addr)
+		if err := http.ListenAndServe(addr, mux); err != nil {
+			log.Printf("pprof: %v", err)
+		}
+	}()
+}